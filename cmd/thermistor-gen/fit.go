@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"github.com/Eriosies/thermistor-lut-gen/pkg/thermistor"
+)
+
+// fitResult holds the fitted model and the coefficients written to the headers.
+type fitResult struct {
+	model     thermistor.Model
+	coeff     []float64
+	beta      [2]float64
+	cvd       [4]float64
+	ptc       []float64
+	segments  []models.Segment
+	fullTable []models.DeviationTable
+	stats     *models.FitStats
+}
+
+// fitNTC fits the thermistor model, fit method and segments selected by cfg.
+func fitNTC(cfg models.Config, points []models.ThermistorPoint) fitResult {
+	var err error
+	var beta [2]float64
+
+	var coeff [3]float64
+	if cfg.Exact {
+		var exactWarnings []string
+		coeff, exactWarnings, err = thermistor.SolveSteinhartExact(points)
+		for _, w := range exactWarnings {
+			log.Printf("Warning: %s", w)
+		}
+		fmt.Println("Exact three-point solution")
+	} else {
+		coeff, err = thermistor.FindSteinhartCoefficients(points)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Steinhart-Hart coefficients:\na = %.3g\nb = %.3g\nc = %.3g\n\n", coeff[0], coeff[1], coeff[2])
+
+	fullTable, maxDev, avgDev := thermistor.CheckDeviation(points, coeff)
+
+	fmt.Printf("Steinhart-Hart deviation from csv\n")
+	fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", maxDev, avgDev)
+
+	model := thermistor.SteinhartModel(coeff)
+	polyCoeff := thermistor.SteinhartToPolynomial(coeff)
+
+	switch cfg.Model {
+	case models.ModelBeta:
+		beta, err = thermistor.FindBetaCoefficients(points)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("\nBeta coefficients:\nR25 = %.1f\nB = %.1f\n\n", beta[0], beta[1])
+
+		var betaMaxDev, betaAvgDev float64
+		fullTable, betaMaxDev, betaAvgDev = thermistor.CheckBetaDeviation(points, beta)
+
+		fmt.Printf("Beta deviation from csv\n")
+		fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", betaMaxDev, betaAvgDev)
+
+		model = thermistor.BetaModel(beta)
+
+	case models.ModelPoly:
+		polyCoeff, err = thermistor.FindPolynomialCoefficients(points, int(cfg.Order))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("\nln(R) polynomial coefficients (order %d):\n", cfg.Order)
+		for k, c := range polyCoeff {
+			fmt.Printf("c%d = %.3g\n", k, c)
+		}
+		fmt.Println()
+
+		var polyMaxDev, polyAvgDev float64
+		fullTable, polyMaxDev, polyAvgDev = thermistor.CheckPolynomialDeviation(points, polyCoeff)
+
+		fmt.Printf("Polynomial deviation from csv\n")
+		fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", polyMaxDev, polyAvgDev)
+
+		model = thermistor.PolynomialModel(polyCoeff)
+	}
+
+	if cfg.Fit != models.FitLeastSquares {
+		var altCoeff []float64
+		var fitName string
+
+		inRange := thermistor.PointsInRange(points, cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+		_, lsqMaxDev, lsqAvgDev := thermistor.CheckModelDeviation(inRange, model)
+
+		switch cfg.Fit {
+		case models.FitMinimax:
+			fitName = "Minimax"
+			altCoeff, _, err = thermistor.FindMinimaxCoefficients(points, modelPowers(cfg), cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+
+		case models.FitLM:
+			var diag thermistor.LMResult
+
+			fitName = "Nonlinear (LM)"
+			seed := polyCoeff
+			if cfg.Model == models.ModelBeta {
+				seed = thermistor.BetaToPolynomial(beta)
+			}
+
+			lower, upper := math.Inf(-1), math.Inf(1)
+			if cfg.FitInRange {
+				lower, upper = cfg.LowerLimitTemp, cfg.UpperLimitTemp
+			}
+
+			altCoeff, diag, err = thermistor.RefineLevenbergMarquardt(points, seed, modelPowers(cfg), lower, upper)
+			if err == nil {
+				fmt.Printf("\nLevenberg-Marquardt refinement (%d points)\n", diag.Points)
+				fmt.Printf("Iterations: %d, Converged: %t, Lambda: %.3g\n", diag.Iterations, diag.Converged, diag.Lambda)
+				fmt.Printf("RMS residual: %.3g K -> %.3g K\n", diag.InitialRMS(), diag.FinalRMS())
+				if !diag.Converged {
+					log.Printf("Warning: Levenberg-Marquardt did not converge in %d iterations", diag.Iterations)
+				}
+			}
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if cfg.Model == models.ModelBeta {
+			beta, err = thermistor.BetaFromPolynomial(altCoeff)
+			if err != nil {
+				log.Fatal(err)
+			}
+			model = thermistor.BetaModel(beta)
+			fmt.Printf("\n%s Beta coefficients:\nR25 = %.1f\nB = %.1f\n", fitName, beta[0], beta[1])
+		} else {
+			polyCoeff = altCoeff
+			model = thermistor.PolynomialModel(polyCoeff)
+			fmt.Printf("\n%s coefficients:\n", fitName)
+			for k, c := range polyCoeff {
+				if c != 0 {
+					fmt.Printf("c%d = %.3g\n", k, c)
+				}
+			}
+		}
+
+		_, altMaxDev, altAvgDev := thermistor.CheckModelDeviation(inRange, model)
+
+		fmt.Printf("\nDeviation from csv between %.1f and %.1f °C (%d points)\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, len(inRange))
+		fmt.Printf("%-15s %-12s %-12s\n", "Fit", "Max (K)", "Avg (K)")
+		fmt.Printf("%-15s %-12.3g %-12.3g\n", "Least squares", lsqMaxDev, lsqAvgDev)
+		fmt.Printf("%-15s %-12.3g %-12.3g\n", fitName, altMaxDev, altAvgDev)
+
+		fullTable, _, _ = thermistor.CheckModelDeviation(points, model)
+	}
+
+	var segments []models.Segment
+	if len(cfg.Bands) != 0 || cfg.Segments > 1 {
+		edges := cfg.Bands
+		if len(edges) == 0 {
+			edges = thermistor.AutoBandEdges(points, int(cfg.Segments))
+		}
+
+		segments, err = thermistor.FindSegmentedCoefficients(points, modelPowers(cfg), edges)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		model = thermistor.SegmentedModel(segments)
+
+		fmt.Printf("\nSegmented fit (%d bands):\n", len(segments))
+		for _, seg := range segments {
+			_, segMaxDev, segAvgDev := thermistor.CheckModelDeviation(thermistor.PointsInRange(points, seg.Lower, seg.Upper), model)
+			fmt.Printf("%6.1f..%-6.1f °C  R >= %-10.1f Max Deviation: %.3g K, Avg Deviation: %.3g K\n", seg.Lower, seg.Upper, seg.Threshold, segMaxDev, segAvgDev)
+		}
+
+		var segMaxDev, segAvgDev float64
+		fullTable, segMaxDev, segAvgDev = thermistor.CheckModelDeviation(points, model)
+
+		fmt.Printf("Segmented deviation from csv\n")
+		fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", segMaxDev, segAvgDev)
+	}
+
+	var stats *models.FitStats
+	if len(segments) == 0 {
+		statsCoeff := polyCoeff
+		if cfg.Model == models.ModelBeta {
+			statsCoeff = thermistor.BetaToPolynomial(beta)
+		}
+
+		fitStats, err := thermistor.FitStatistics(points, modelPowers(cfg), statsCoeff)
+		if err != nil {
+			log.Printf("Warning: %s", err)
+		}
+		stats = &fitStats
+
+		printStats(fitStats)
+	}
+
+	return fitResult{
+		model:     model,
+		coeff:     polyCoeff,
+		beta:      beta,
+		segments:  segments,
+		fullTable: fullTable,
+		stats:     stats,
+	}
+}
+
+// fitRTD fits the Callendar-Van Dusen coefficients, or R0 alone when cfg gives {A, B, C}.
+func fitRTD(cfg models.Config, points []models.ThermistorPoint) fitResult {
+	var cvd [4]float64
+	var err error
+
+	switch {
+	case cfg.CVDCoeff != nil && cfg.R0 != 0:
+		cvd = [4]float64{cfg.R0, cfg.CVDCoeff[0], cfg.CVDCoeff[1], cfg.CVDCoeff[2]}
+		fmt.Println("Callendar-Van Dusen coefficients (given):")
+	case cfg.CVDCoeff != nil:
+		cvd, err = thermistor.FindCVDR0(points, [3]float64{cfg.CVDCoeff[0], cfg.CVDCoeff[1], cfg.CVDCoeff[2]})
+		fmt.Println("Callendar-Van Dusen coefficients (R0 fitted):")
+	default:
+		cvd, err = thermistor.FindCVDCoefficients(points)
+		fmt.Println("Callendar-Van Dusen coefficients:")
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("R0 = %.3f\nA = %.6g\nB = %.6g\nC = %.6g\n\n", cvd[0], cvd[1], cvd[2], cvd[3])
+
+	fullTable, maxDev, avgDev := thermistor.CheckCVDDeviation(points, cvd)
+
+	fmt.Printf("Callendar-Van Dusen deviation from csv\n")
+	fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", maxDev, avgDev)
+
+	return fitResult{
+		model:     thermistor.CVDModel(cvd),
+		cvd:       cvd,
+		fullTable: fullTable,
+	}
+}
+
+// fitPTC fits the silicon PTC polynomial R(T) of order cfg.PTCOrder.
+func fitPTC(cfg models.Config, points []models.ThermistorPoint) fitResult {
+	ptc, err := thermistor.FindPTCCoefficients(points, int(cfg.PTCOrder))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("PTC coefficients, R = Σ ck·(T - %.0f)^k:\n", models.PTCReferenceTemp)
+	for k, c := range ptc {
+		fmt.Printf("c%d = %.6g\n", k, c)
+	}
+	fmt.Println()
+
+	fullTable, maxDev, avgDev := thermistor.CheckPTCDeviation(points, ptc)
+
+	fmt.Printf("PTC deviation from csv\n")
+	fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", maxDev, avgDev)
+
+	return fitResult{
+		model:     thermistor.PTCModel(ptc),
+		ptc:       ptc,
+		fullTable: fullTable,
+	}
+}
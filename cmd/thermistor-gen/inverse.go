@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Eriosies/thermistor-lut-gen/internal/csvparser"
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"github.com/Eriosies/thermistor-lut-gen/pkg/thermistor"
+)
+
+// runInverse prints and writes the resistance and expected ADC code at each requested temperature,
+// and the code at each -vrefs measured reference.
+func runInverse(cfg models.Config, baseName string, model thermistor.Model) error {
+	temps := tabulationTemps(cfg)
+	fullScale := float64(uint(1) << cfg.ADCResolution)
+	if cfg.Topology == models.TopologyBridge {
+		fullScale /= 2
+	}
+
+	header := "Temperature (°C),Resistance (Ω),ADC Value,Vout (V)"
+	fmt.Printf("\n%-12s %-14s %-10s %-10s", "Temp (°C)", "Resistance (Ω)", "ADC Code", "Vout (V)")
+	for _, vref := range cfg.InverseVrefs {
+		header += fmt.Sprintf(",ADC Value @ %gV", vref)
+		fmt.Printf(" %-10s", fmt.Sprintf("@ %gV", vref))
+	}
+	fmt.Println()
+
+	var rows [][]string
+	for _, t := range temps {
+		adc, resistance, err := thermistor.ADCValueFromTemperature(cfg, model, t)
+		if err != nil {
+			return err
+		}
+		code := models.SignedADCValue(cfg, adc)
+		vOut := models.ADCReference(cfg) * float64(code) / fullScale
+
+		fmt.Printf("%-12.2f %-14.2f %-10d %-10.4f", t, resistance, code, vOut)
+		row := []string{
+			fmt.Sprintf("%.2f", t),
+			fmt.Sprintf("%.3f", resistance),
+			fmt.Sprintf("%d", code),
+			fmt.Sprintf("%.4f", vOut),
+		}
+
+		for _, vref := range cfg.InverseVrefs {
+			adc, err := thermistor.ADCValueAtVref(cfg, model, t, vref)
+			if err != nil {
+				return err
+			}
+			fmt.Printf(" %-10d", models.SignedADCValue(cfg, adc))
+			row = append(row, fmt.Sprintf("%d", models.SignedADCValue(cfg, adc)))
+		}
+		fmt.Println()
+		rows = append(rows, row)
+	}
+
+	inverseCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_inverse.csv", baseName))
+	if err := csvparser.WriteCSV(inverseCSV, header, rows); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nInverse table: %s\n\n", inverseCSV)
+	return nil
+}
+
+// tabulationTemps returns the -t temperatures, or -tl..-tu in steps of -step.
+func tabulationTemps(cfg models.Config) []float64 {
+	temps := cfg.InverseTemps
+	if len(temps) == 0 {
+		for t := cfg.LowerLimitTemp; t <= cfg.UpperLimitTemp+1e-9; t += cfg.InverseStep {
+			temps = append(temps, t)
+		}
+	}
+	return temps
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/Eriosies/thermistor-lut-gen/internal/ccode"
+	"github.com/Eriosies/thermistor-lut-gen/internal/csvparser"
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"github.com/Eriosies/thermistor-lut-gen/pkg/thermistor"
+)
+
+// runLinearise designs the most linear divider, tabulates the error of reading it as a straight
+// line, and writes the linear C function if the error is within -lintol.
+func runLinearise(cfg models.Config, baseName string, model thermistor.Model, metadata [][2]string, analysis ccode.Analysis) error {
+	var rs float64
+	if cfg.RSGiven {
+		rs = cfg.RS * 1000
+	}
+
+	l, err := thermistor.LineariseDivider(cfg, model, cfg.ESeries, rs)
+	if err != nil {
+		return err
+	}
+
+	cfg.RS, cfg.RP = l.RS/1000, l.RP/1000
+
+	fmt.Printf("\nLinearised divider between %.1f and %.1f °C, resistors from %s\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, cfg.ESeries)
+	fmt.Printf("Series resistor: %.4gk\n", cfg.RS)
+	if cfg.RP == 0 {
+		fmt.Println("Parallel resistor: none")
+	} else {
+		fmt.Printf("Parallel resistor: %.4gk\n", cfg.RP)
+		fmt.Printf("Effective source resistance RS‖RP: %.4gk, full scale reduced to %.1f%%\n", cfg.RS*cfg.RP/(cfg.RS+cfg.RP), 100*cfg.RP/(cfg.RS+cfg.RP))
+	}
+	fmt.Printf("Temperature = %.6g * ADC + %.6g °C\n", l.Gain, l.Offset)
+	fmt.Printf("Worst linearity error: %.3g K at %.1f °C\n", l.MaxError, l.MaxAt)
+
+	fmt.Printf("\n%-12s %-10s %-14s %-10s\n", "Temp (°C)", "ADC Code", "Linear (°C)", "Error (K)")
+
+	var rows [][]string
+	for _, t := range tabulationTemps(cfg) {
+		adc, _, err := thermistor.ADCValueFromTemperature(cfg, model, t)
+		if err != nil {
+			return err
+		}
+		linear := l.Gain*float64(adc) + l.Offset
+
+		fmt.Printf("%-12.2f %-10d %-14.3f %-10.3f\n", t, adc, linear, linear-t)
+		rows = append(rows, []string{
+			fmt.Sprintf("%.2f", t),
+			fmt.Sprintf("%d", adc),
+			fmt.Sprintf("%.3f", linear),
+			fmt.Sprintf("%.3f", linear-t),
+		})
+	}
+
+	linearCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_linear.csv", baseName))
+	if err := csvparser.WriteCSV(linearCSV, "Temperature (°C),ADC Value,Linear Temp (°C),Error (K)", rows); err != nil {
+		return err
+	}
+	fmt.Printf("\nLinearity table: %s\n", linearCSV)
+
+	if cfg.LinearTol != 0 {
+		if l.MaxError > cfg.LinearTol {
+			log.Printf("Warning: linearity error %.3g K exceeds -lintol %.3g K; no linear header written.", l.MaxError, cfg.LinearTol)
+		} else {
+			linearCFile := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_linear.h", strings.ToLower(baseName)))
+			if err := ccode.GenerateLinearCcode(linearCFile, l.Gain, l.Offset, l.MaxError, metadata, cfg, analysis); err != nil {
+				return err
+			}
+			fmt.Printf("Linear C header: %s\n", linearCFile)
+		}
+	}
+	fmt.Println()
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/Eriosies/thermistor-lut-gen/internal/ccode"
 	"github.com/Eriosies/thermistor-lut-gen/internal/csvparser"
@@ -12,25 +15,96 @@ import (
 	"github.com/Eriosies/thermistor-lut-gen/pkg/thermistor"
 )
 
+const (
+	commandInverse    = "inverse"
+	commandMonteCarlo = "montecarlo"
+	commandOptimise   = "optimise"
+	commandLinearise  = "linearise"
+)
+
 func parseFlags() models.Config {
+	var bands, inverseTemps, cvd, lutSize, bridge, inverseVrefs string
 	cfg := models.Config{}
 
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == commandInverse || args[0] == commandMonteCarlo || args[0] == commandOptimise || args[0] == commandLinearise) {
+		cfg.Command = args[0]
+		args = args[1:]
+	}
+
 	flag.StringVar(&cfg.InputFile, "i", "", "Input CSV file path")
 	flag.StringVar(&cfg.OutputDir, "o", "./output", "Output directory")
 	flag.StringVar(&cfg.NameFlag, "n", "", "Base name for generated files (optional)")
-	flag.UintVar(&cfg.LUTSize, "lut", 0, "LUT size (power of 2) or 0 for Steinhart.h only (default 0)")
+	flag.StringVar(&lutSize, "lut", "0", "LUT size (power of 2), auto for the smallest size within -maxerr, or 0 for Steinhart.h only")
+	flag.Float64Var(&cfg.MaxLUTError, "maxerr", 0, "-lut auto: largest LUT error (K) allowed between -tl and -tu")
 	flag.UintVar(&cfg.ADCResolution, "a", 12, "ADC resolution in bits")
 	flag.Float64Var(&cfg.VoltageRef, "v", 3.3, "ADC reference voltage (V)")
+	flag.BoolVar(&cfg.RuntimeVref, "runtimevref", false, "Generate C taking the measured ADC reference (_get_resistance_vref, _get_temp_vref) for runtime compensation")
+	flag.Float64Var(&cfg.VrefintCal, "vrefcal", 3.0, "-runtimevref: ADC reference (V) the VREFINT calibration value was taken at")
+	flag.Float64Var(&cfg.VoltageSupply, "vsupply", 0, "Divider or bridge supply voltage (V) when it is not the ADC reference, 0 = -v (default 0)")
 	flag.Float64Var(&cfg.RS, "rs", 10.0, "Series resistance (kΩ)")
 	flag.Float64Var(&cfg.RP, "rp", 0.0, "Parallel resistance (kΩ), 0 = none (default 0)")
+	flag.Float64Var(&cfg.RSource, "rsrc", 0, "Protection or filter resistor between the circuit and the ADC pin (kΩ) (default 0)")
+	flag.Float64Var(&cfg.RInput, "rin", 0, "ADC input resistance (kΩ), e.g. of the sampling capacitor load, 0 = none (default 0)")
+	flag.Float64Var(&cfg.Leakage, "ileak", 0, "ADC pin leakage current (nA), positive into the pin (default 0)")
+	flag.Float64Var(&cfg.ADCOffset, "adcoffset", 0, "ADC offset error (LSB), corrected in the LUT (default 0)")
+	flag.Float64Var(&cfg.ADCGain, "adcgain", 0, "ADC gain error (ppm), corrected in the LUT (default 0)")
+	flag.StringVar(&cfg.INLFile, "inlcsv", "", "ADC INL table (CSV with Code,INL columns in LSB), corrected in the LUT")
+	flag.StringVar(&cfg.Topology, "topology", models.TopologyLow, "Circuit topology: low for the thermistor to ground, high for the thermistor to Vref with rs as pull-down, bridge for a Wheatstone bridge read by a differential ADC, or current for a constant current source")
+	flag.StringVar(&bridge, "bridge", "10,10", "bridge: reference leg resistors to Vref and to ground (kΩ), e.g. 10,10")
+	flag.Float64Var(&cfg.AmpGain, "gain", 1, "bridge: amplifier gain (V/V)")
+	flag.Float64Var(&cfg.AmpOffset, "ampoffset", 0, "bridge: amplifier output offset (V)")
+	flag.Float64Var(&cfg.Excitation, "iexc", 100, "current: excitation current (µA)")
+	flag.Float64Var(&cfg.RRef, "rref", 0, "current: ratiometric reference resistor (kΩ) setting the ADC reference, 0 = none, the ADC uses -v (default 0)")
 	flag.Float64Var(&cfg.UpperLimitTemp, "tu", 125.0, "Upper temperature limit (°C)")
 	flag.Float64Var(&cfg.LowerLimitTemp, "tl", -40.0, "Lower temperature limit (°C)")
 	flag.UintVar(&cfg.FixedPoint, "fp", 0, "Adjust the position of the fixed point in the uint LUT table (default 0)")
+	flag.StringVar(&cfg.Model, "model", models.ModelSteinhart, "Thermistor model used for the LUT: steinhart, beta or poly")
+	flag.UintVar(&cfg.Order, "order", 3, "Order N of the ln(R) polynomial 1, lnR, ..., lnR^N used by -model poly")
+	flag.StringVar(&cfg.Fit, "fit", models.FitLeastSquares, "Fit method: lsq, minimax to minimise the max deviation between -tl and -tu, or lm for a nonlinear fit in °C")
+	flag.BoolVar(&cfg.FitInRange, "fitrange", false, "Restrict the -fit lm refinement to points between -tl and -tu")
+	flag.UintVar(&cfg.Segments, "segments", 0, "Fit N temperature bands with separate coefficients, 0 or 1 = single fit (default 0)")
+	flag.StringVar(&bands, "bands", "", "Comma separated band edge temperatures (°C) for a segmented fit, e.g. 0,60")
+	flag.BoolVar(&cfg.Exact, "exact", false, "Solve the Steinhart-Hart equation exactly through a CSV of exactly 3 calibration points")
+	flag.StringVar(&cfg.Robust, "robust", "", "Outlier resistant fit for measured data: huber or tukey (default none)")
+	flag.BoolVar(&cfg.ExcludeOutlier, "exclude", false, "Exclude suspected outliers found by -robust from the final fit")
+	flag.StringVar(&cfg.Weighting, "weight", models.WeightNone, "Fit weighting strategy: none, or range to emphasise points between -tl and -tu")
+	flag.StringVar(&cfg.Sensor, "sensor", models.SensorAuto, "Sensor type: auto to detect ntc, ptc or rtd from the CSV Type row and table slope, ntc, ptc for a silicon KTY sensor, or rtd for a platinum RTD (PT100/PT1000)")
+	flag.UintVar(&cfg.PTCOrder, "ptcorder", uint(thermistor.PTCDefaultOrder), "ptc: order of the R(T) polynomial")
+	flag.StringVar(&cvd, "cvd", "fit", "rtd: Callendar-Van Dusen coefficients: fit, iec for IEC 60751, or A,B,C")
+	flag.Float64Var(&cfg.R0, "r0", 0, "rtd: resistance at 0 °C (Ω) used with -cvd iec or A,B,C, 0 = fit from the CSV (default 0)")
+	flag.Float64Var(&cfg.Dissipation, "dc", 0, "Thermistor dissipation constant (mW/K), 0 = from the CSV Dissipation_Constant metadata (default 0)")
+	flag.BoolVar(&cfg.SelfHeating, "selfheat", false, "Correct the LUT and headers for self-heating using the dissipation constant")
+	flag.Float64Var(&cfg.TolR25, "tolr25", 0, "Thermistor resistance tolerance (%) for the worst-case LUT analysis")
+	flag.Float64Var(&cfg.TolB, "tolb", 0, "Thermistor B constant tolerance (%) for the worst-case LUT analysis")
+	flag.Float64Var(&cfg.TolRS, "tolrs", 0, "Series resistor tolerance (%) for the worst-case LUT analysis")
+	flag.StringVar(&inverseTemps, "t", "", "inverse, montecarlo: comma separated temperatures (°C), default tabulates -tl..-tu")
+	flag.Float64Var(&cfg.InverseStep, "step", 5.0, "inverse, montecarlo: tabulation step (°C)")
+	flag.StringVar(&inverseVrefs, "vrefs", "", "inverse: comma separated measured ADC references (V) to also tabulate codes at, e.g. 3.0,3.3,3.6")
+	flag.IntVar(&cfg.Trials, "trials", 10000, "montecarlo: number of simulated boards")
+	flag.Int64Var(&cfg.Seed, "seed", 1, "montecarlo: random seed")
+	flag.Float64Var(&cfg.TolRP, "tolrp", 0, "montecarlo: parallel resistor tolerance (%)")
+	flag.Float64Var(&cfg.VrefTol, "vreftol", 0, "montecarlo: mismatch between the divider supply and the ADC reference (%)")
+	flag.Float64Var(&cfg.ADCNoise, "noise", 0, "montecarlo: ADC noise (LSB rms)")
+	flag.Float64Var(&cfg.ADCINL, "inl", 0, "montecarlo: ADC integral non-linearity (LSB peak)")
+	flag.StringVar(&cfg.OptMetric, "metric", thermistor.MetricSensitivity, "optimise: rank dividers by minimum sensitivity or by worst case quantisation error")
+	flag.StringVar(&cfg.ESeries, "eseries", thermistor.ESeriesNone, "optimise, linearise: snap resistors to e24 or e96 values, or none")
+	flag.BoolVar(&cfg.OptimiseRP, "optrp", false, "optimise: also search a parallel resistor")
+	flag.IntVar(&cfg.Top, "top", 5, "optimise: number of candidates to print")
+	flag.Float64Var(&cfg.LinearTol, "lintol", 0, "linearise: write a linear C function if the linearity error is within this many K, 0 = report only (default 0)")
 
 	flag.Usage = func() {
 		fmt.Println("Thermistor LUT Generator")
 		fmt.Println("Usage: thermistor-gen -i input.csv -o output_dir [-n base_name] [options]")
-		fmt.Println("\nVoltage divider schematic (rs in series, thermistor with optional parallel rp):")
+		fmt.Println("       thermistor-gen inverse -i input.csv [-t 25,50 | -tl -20 -tu 80 -step 5] [options]")
+		fmt.Println("       thermistor-gen montecarlo -i input.csv [-trials 10000 -tolr25 1 -tolb 1 -tolrs 0.1 -noise 1] [options]")
+		fmt.Println("       thermistor-gen optimise -i input.csv -tl 0 -tu 100 [-metric sensitivity|quantisation -eseries e96 -optrp] [options]")
+		fmt.Println("       thermistor-gen linearise -i input.csv -tl 0 -tu 100 [-eseries e96 -lintol 0.5] [options]")
+		fmt.Println("\nThe inverse command prints the thermistor resistance and expected ADC code for each temperature.")
+		fmt.Println("The montecarlo command simulates component tolerances and ADC errors and reports the temperature error distribution.")
+		fmt.Println("The optimise command searches for the series (and parallel) resistor that reads best between -tl and -tu.")
+		fmt.Println("The linearise command designs the series and parallel resistors that make the ADC code most linear in temperature.")
+		fmt.Println("\nVoltage divider schematic, -topology low (rs in series, thermistor with optional parallel rp):")
 		fmt.Println(`
 	Vref
 	|
@@ -44,12 +118,58 @@ func parseFlags() models.Config {
 	|                   |
 	+-------------------+
 	| 
+	GND`)
+		fmt.Println("\nVoltage divider schematic, -topology high (thermistor with optional parallel rp, rs as pull-down):")
+		fmt.Println(`
+	Vref
+	|
+	+-------------------+
+	|                   |
+	[Thermistor]        [Parallel Resistor] (optional)
+	|                   |
+	+-------------------+------ Vout
+	|
+	|
+	[Series Resistor]
+	|
+	|
+	GND`)
+		fmt.Println("\nBridge schematic, -topology bridge (the low divider as one leg, -bridge top,bottom as the other):")
+		fmt.Println(`
+	Vref ---------+-------------------+
+	              |                   |
+	              [Series Resistor]   [Bridge Top]
+	              |                   |
+	              +---- IN+   IN- ----+
+	              |    [Amplifier]    |
+	              [Thermistor]        [Bridge Bottom]
+	              |         |         |
+	GND ----------+---------|---------+
+	                        |
+	                   Differential ADC (signed)`)
+		fmt.Println("\nCurrent source schematic, -topology current (the ADC reference is the drop across -rref if given, else -v):")
+		fmt.Println(`
+	[Current Source]
+	|
+	+-------------------+------ Vout
+	|                   |
+	[Thermistor]        [Parallel Resistor] (optional)
+	|                   |
+	+-------------------+------ REF+
+	|
+	[Reference Resistor] (optional)
+	|
 	GND`)
 		fmt.Println("\nFlags:")
 		flag.PrintDefaults()
 	}
 
-	flag.Parse()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		cfg.RSGiven = cfg.RSGiven || f.Name == "rs"
+	})
 
 	if cfg.InputFile == "" {
 		log.Fatal("Input file is required. Use -help for more information.")
@@ -68,10 +188,181 @@ func parseFlags() models.Config {
 		log.Fatalf("Output path exists but is not a directory: %s", cfg.OutputDir)
 	}
 
+	if lutSize == "auto" {
+		cfg.LUTAuto = true
+		if cfg.MaxLUTError <= 0 {
+			log.Fatal("-lut auto needs a -maxerr target greater than 0.")
+		}
+	} else {
+		size, err := strconv.ParseUint(lutSize, 10, 0)
+		if err != nil {
+			log.Fatalf("Invalid -lut %q. Use a power of 2, auto or 0.", lutSize)
+		}
+		cfg.LUTSize = uint(size)
+		if cfg.MaxLUTError != 0 {
+			log.Fatal("-maxerr is only used with -lut auto.")
+		}
+	}
+
 	if cfg.LUTSize != 0 && (cfg.LUTSize&(cfg.LUTSize-1)) != 0 {
 		log.Fatal("LUT size must be a power of 2, or 0 for no LUT generation. e.g. 256, 512, 1024...")
 	}
 
+	switch cfg.Model {
+	case models.ModelSteinhart, models.ModelBeta:
+	case models.ModelPoly:
+		if cfg.Order < 1 {
+			log.Fatal("Polynomial order must be at least 1.")
+		}
+	default:
+		log.Fatalf("Unknown model %q. Use steinhart, beta or poly.", cfg.Model)
+	}
+
+	switch cfg.Fit {
+	case models.FitLeastSquares, models.FitMinimax, models.FitLM:
+	default:
+		log.Fatalf("Unknown fit method %q. Use lsq, minimax or lm.", cfg.Fit)
+	}
+
+	cfg.Bands, err = parseFloatList(bands)
+	if err != nil {
+		log.Fatalf("Invalid -bands: %v", err)
+	}
+
+	cfg.InverseTemps, err = parseFloatList(inverseTemps)
+	if err != nil {
+		log.Fatalf("Invalid -t: %v", err)
+	}
+
+	cfg.InverseVrefs, err = parseFloatList(inverseVrefs)
+	if err != nil {
+		log.Fatalf("Invalid -vrefs: %v", err)
+	}
+	for _, v := range cfg.InverseVrefs {
+		if v <= 0 {
+			log.Fatal("-vrefs must be greater than 0.")
+		}
+	}
+
+	if cfg.RuntimeVref && cfg.VrefintCal <= 0 {
+		log.Fatal("-vrefcal must be greater than 0.")
+	}
+
+	if cfg.Command != "" && len(cfg.InverseTemps) == 0 && cfg.InverseStep <= 0 {
+		log.Fatal("-step must be greater than 0.")
+	}
+
+	if len(cfg.Bands) != 0 || cfg.Segments > 1 {
+		if cfg.Model == models.ModelBeta || cfg.Fit != models.FitLeastSquares {
+			log.Fatal("Segmented fits are only supported with -model steinhart or poly and -fit lsq.")
+		}
+	}
+
+	if cfg.Exact && (cfg.Model != models.ModelSteinhart || cfg.Fit != models.FitLeastSquares || len(cfg.Bands) != 0 || cfg.Segments > 1) {
+		log.Fatal("-exact only supports -model steinhart with -fit lsq and no segments.")
+	}
+
+	if cfg.Robust != "" && cfg.Robust != thermistor.RobustHuber && cfg.Robust != thermistor.RobustTukey {
+		log.Fatalf("Unknown robust method %q. Use huber or tukey.", cfg.Robust)
+	}
+
+	if cfg.ExcludeOutlier && cfg.Robust == "" {
+		log.Fatal("-exclude requires -robust huber or tukey.")
+	}
+
+	if cfg.VoltageSupply < 0 {
+		log.Fatal("-vsupply cannot be negative.")
+	}
+
+	if cfg.RSource < 0 || cfg.RInput < 0 {
+		log.Fatal("-rsrc and -rin cannot be negative.")
+	}
+
+	switch cfg.Topology {
+	case models.TopologyLow, models.TopologyHigh:
+	case models.TopologyBridge:
+		legs, err := parseFloatList(bridge)
+		if err != nil || len(legs) != 2 || legs[0] <= 0 || legs[1] <= 0 {
+			log.Fatalf("Invalid -bridge %q. Use two positive resistances in kΩ, e.g. 10,10.", bridge)
+		}
+		cfg.BridgeTop, cfg.BridgeBottom = legs[0], legs[1]
+
+		if cfg.AmpGain <= 0 {
+			log.Fatal("-gain must be greater than 0.")
+		}
+		if cfg.Command == commandOptimise || cfg.Command == commandLinearise {
+			log.Fatalf("The %s command only supports -topology low or high.", cfg.Command)
+		}
+	case models.TopologyCurrent:
+		if cfg.Excitation <= 0 {
+			log.Fatal("-iexc must be greater than 0.")
+		}
+		if cfg.RRef < 0 {
+			log.Fatal("-rref cannot be negative.")
+		}
+		if cfg.Command == commandOptimise || cfg.Command == commandLinearise {
+			log.Fatalf("The %s command only supports -topology low or high.", cfg.Command)
+		}
+	default:
+		log.Fatalf("Unknown topology %q. Use low, high, bridge or current.", cfg.Topology)
+	}
+
+	switch cfg.Sensor {
+	case models.SensorAuto, models.SensorNTC, models.SensorRTD:
+	case models.SensorPTC:
+		if cfg.PTCOrder < 1 {
+			log.Fatal("PTC polynomial order must be at least 1.")
+		}
+	default:
+		log.Fatalf("Unknown sensor %q. Use auto, ntc, ptc or rtd.", cfg.Sensor)
+	}
+
+	switch cvd {
+	case "fit":
+	case "iec":
+		cfg.CVDCoeff = thermistor.IECCVD[:]
+	default:
+		cfg.CVDCoeff, err = parseFloatList(cvd)
+		if err != nil || len(cfg.CVDCoeff) != 3 {
+			log.Fatalf("Invalid -cvd %q. Use fit, iec or three comma separated coefficients A,B,C.", cvd)
+		}
+	}
+
+	if cfg.R0 < 0 || (cfg.R0 != 0 && cfg.CVDCoeff == nil) {
+		log.Fatal("-r0 must be positive and is only used with -cvd iec or A,B,C.")
+	}
+
+	if cfg.Dissipation < 0 {
+		log.Fatal("-dc must be positive.")
+	}
+
+	if cfg.TolR25 < 0 || cfg.TolB < 0 || cfg.TolRS < 0 || cfg.TolRP < 0 || cfg.VrefTol < 0 || cfg.ADCNoise < 0 || cfg.ADCINL < 0 {
+		log.Fatal("Tolerances must be positive.")
+	}
+
+	if cfg.Command == commandMonteCarlo && cfg.Trials < 1 {
+		log.Fatal("-trials must be at least 1.")
+	}
+
+	if cfg.Command == commandOptimise || cfg.Command == commandLinearise {
+		if cfg.OptMetric != thermistor.MetricSensitivity && cfg.OptMetric != thermistor.MetricQuantisation {
+			log.Fatalf("Unknown metric %q. Use sensitivity or quantisation.", cfg.OptMetric)
+		}
+		if cfg.ESeries != thermistor.ESeriesNone && cfg.ESeries != thermistor.ESeriesE24 && cfg.ESeries != thermistor.ESeriesE96 {
+			log.Fatalf("Unknown E series %q. Use none, e24 or e96.", cfg.ESeries)
+		}
+		if cfg.Top < 1 {
+			log.Fatal("-top must be at least 1.")
+		}
+		if cfg.LinearTol < 0 {
+			log.Fatal("-lintol must be positive.")
+		}
+	}
+
+	if (cfg.TolR25 != 0 || cfg.TolB != 0 || cfg.TolRS != 0) && cfg.LUTSize == 0 && !cfg.LUTAuto && cfg.Command == "" {
+		log.Fatal("The tolerance analysis is made per LUT entry and needs -lut.")
+	}
+
 	if cfg.LUTSize > (1 << cfg.ADCResolution) {
 		log.Fatalf("LUT size (%d) cannot exceed ADC maximum (%d).", cfg.LUTSize, 1<<cfg.ADCResolution)
 	}
@@ -79,6 +370,90 @@ func parseFlags() models.Config {
 	return cfg
 }
 
+// checkSensorOptions rejects the NTC only fit options once the sensor type is known.
+func checkSensorOptions(cfg models.Config) {
+	if cfg.Sensor == models.SensorNTC {
+		return
+	}
+	if cfg.Model != models.ModelSteinhart || cfg.Fit != models.FitLeastSquares || len(cfg.Bands) != 0 || cfg.Segments > 1 || cfg.Exact || cfg.Robust != "" {
+		log.Fatalf("-sensor %s does not support -model, -fit, -segments, -bands, -exact or -robust.", cfg.Sensor)
+	}
+}
+
+// selectLUTSize prints the error and memory use of each LUT size tried for -lut auto and
+// returns the smallest within -maxerr.
+func selectLUTSize(cfg models.Config, model thermistor.Model) (uint, error) {
+	size, errs, err := thermistor.SelectLUTSize(cfg, model, cfg.MaxLUTError)
+
+	fmt.Printf("\nLUT size selection (max error %.3g K between %.1f and %.1f °C)\n", cfg.MaxLUTError, cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	fmt.Printf("%-8s %-12s %-14s %-14s\n", "Size", "Error (K)", "Float (bytes)", "Int (bytes)")
+	for i, e := range errs {
+		cfg.LUTSize = 2 << i
+		lut, _, _, lutErr := thermistor.GenerateModelLUT(cfg, model)
+		if lutErr != nil {
+			return 0, lutErr
+		}
+		intType, intBytes := ccode.LUTIntType(lut, cfg.FixedPoint)
+		fmt.Printf("%-8d %-12.4f %-14d %-14s\n", cfg.LUTSize, e, 4*cfg.LUTSize, fmt.Sprintf("%d (%s)", intBytes*int(cfg.LUTSize), intType))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("Selected LUT size: %d\n", size)
+	return size, nil
+}
+
+func parseFloatList(list string) ([]float64, error) {
+	var values []float64
+	if list == "" {
+		return nil, nil
+	}
+
+	for _, field := range strings.Split(list, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func modelPowers(cfg models.Config) []int {
+	switch cfg.Model {
+	case models.ModelBeta:
+		return thermistor.BetaPowers
+	case models.ModelPoly:
+		return thermistor.PolynomialPowers(int(cfg.Order))
+	default:
+		return thermistor.SteinhartPowers
+	}
+}
+
+func printStats(stats models.FitStats) {
+	fmt.Printf("\nFit statistics (%d points, %d degrees of freedom)\n", stats.Points, stats.DOF)
+	fmt.Printf("RMS error: %.3g K, R²: %.8f, Condition number: %.3g\n", stats.RMS, stats.RSquared, stats.Condition)
+
+	for i, power := range stats.Powers {
+		if stats.StdErr == nil {
+			fmt.Printf("c%d = %.6g\n", power, stats.Coeff[i])
+			continue
+		}
+		fmt.Printf("c%d = %.6g ± %.3g (%.2g%%)\n", power, stats.Coeff[i], stats.StdErr[i], 100*stats.StdErr[i]/math.Abs(stats.Coeff[i]))
+	}
+
+	var widest models.ConfidencePoint
+	for _, p := range stats.Band {
+		if p.HalfWidth > widest.HalfWidth {
+			widest = p
+		}
+	}
+	if stats.Band != nil {
+		fmt.Printf("Widest %.0f%% confidence band: ±%.3g K at %.1f °C\n", stats.ConfidenceLevel*100, widest.HalfWidth, widest.Temperature)
+	}
+}
+
 func main() {
 	var err error
 	var tempLUT, resistanceLUT []float64
@@ -86,18 +461,84 @@ func main() {
 
 	cfg := parseFlags()
 
+	lutDescription := fmt.Sprintf("%d", cfg.LUTSize)
+	if cfg.LUTAuto {
+		lutDescription = fmt.Sprintf("auto (max error %.3g K)", cfg.MaxLUTError)
+	}
+
 	fmt.Println("\nThermistor C Code LUT Generator")
 	fmt.Println("------------------------------------")
 	fmt.Printf(
-		"Input File: %s\nOutput Directory: %s\nLUT Size: %d\nADC Resolution: %d bit\nADC Reference Voltage: %.2fV\nResistor Series: %.2fk\nResistor Parallel: %.2fk\n",
-		cfg.InputFile, cfg.OutputDir, cfg.LUTSize, cfg.ADCResolution, cfg.VoltageRef, cfg.RS, cfg.RP,
+		"Input File: %s\nOutput Directory: %s\nLUT Size: %s\nADC Resolution: %d bit\nADC Reference Voltage: %.2fV\nResistor Series: %.2fk\nResistor Parallel: %.2fk\nTopology: %s\n",
+		cfg.InputFile, cfg.OutputDir, lutDescription, cfg.ADCResolution, cfg.VoltageRef, cfg.RS, cfg.RP, cfg.Topology,
 	)
+	if cfg.VoltageSupply != 0 && cfg.Topology != models.TopologyCurrent {
+		fmt.Printf("Supply Voltage: %.2fV\n", cfg.VoltageSupply)
+	}
+	if cfg.Topology == models.TopologyCurrent {
+		fmt.Printf("Excitation Current: %gµA\nReference Resistor: %.2fk (ADC reference %.3fV)\n", cfg.Excitation, cfg.RRef, models.ADCReference(cfg))
+	}
 
 	points, metadata, warnings, err := csvparser.ReadCSV(cfg.InputFile)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if cfg.INLFile != "" {
+		if cfg.INL, err = csvparser.ParseINLCSV(cfg.INLFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	detected := csvparser.DetectSensor(points, metadata)
+	switch {
+	case cfg.Sensor == models.SensorAuto:
+		cfg.Sensor = detected
+	case cfg.Sensor == models.SensorNTC && detected != models.SensorNTC:
+		warnings = append(warnings, "Resistance rises with temperature but -sensor ntc was given; use -sensor ptc or rtd.")
+	case cfg.Sensor != models.SensorNTC && detected == models.SensorNTC:
+		warnings = append(warnings, fmt.Sprintf("Resistance falls with temperature but -sensor %s was given.", cfg.Sensor))
+	}
+	checkSensorOptions(cfg)
+
+	if !models.Ratiometric(cfg) {
+		if cfg.Topology == models.TopologyCurrent {
+			warnings = append(warnings, "Without -rref the reading is not ratiometric: its accuracy depends on the excitation current and the ADC reference.")
+		} else {
+			warnings = append(warnings, fmt.Sprintf("The %.2fV supply is not the %.2fV ADC reference, so the reading is not ratiometric: supply tolerance adds directly to the temperature error (see montecarlo -vreftol).", cfg.VoltageSupply, cfg.VoltageRef))
+		}
+	}
+
+	cfg.Dissipation, err = models.DetermineDissipation(cfg, metadata)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.SelfHeating && cfg.Dissipation == 0 {
+		log.Fatal("-selfheat needs a dissipation constant from -dc or the CSV Dissipation_Constant metadata.")
+	}
+
+	points, err = thermistor.WeightPoints(points, cfg.Weighting, cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var outliers []thermistor.Outlier
+	if cfg.Robust != "" {
+		points, outliers, err = thermistor.RobustWeights(points, modelPowers(cfg), cfg.Robust)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, o := range outliers {
+			warnings = append(warnings, fmt.Sprintf("Suspected outlier at point %d (%.2f °C, %.1f Ω): residual %.3g K, %.1f robust σ.", o.Index+1, o.Point.Temp, o.Point.Resistance, o.Residual, o.Sigmas))
+		}
+
+		if cfg.ExcludeOutlier && len(outliers) != 0 {
+			points = thermistor.ExcludeOutliers(points, outliers)
+			warnings = append(warnings, fmt.Sprintf("Excluded %d suspected outlier(s) from the fit.", len(outliers)))
+		}
+	}
+
 	for _, w := range warnings {
 		log.Printf("Warning: %s", w)
 	}
@@ -106,32 +547,144 @@ func main() {
 	for _, m := range metadata {
 		fmt.Printf("%s - %s\n", m[0], m[1])
 	}
-	fmt.Printf("\nNumber of points = %d\n\n", len(points))
+	fmt.Printf("\nNumber of points = %d\nSensor = %s\n\n", len(points), cfg.Sensor)
 
-	coeff, err := thermistor.FindSteinhartCoefficients(points)
+	if points[0].Uncertainty != 0 {
+		fmt.Println("Fit weighted by CSV uncertainty column")
+	}
+	if cfg.Weighting == models.WeightRange {
+		fmt.Printf("Fit weighted to %.1f..%.1f °C (outside weight x%g)\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, thermistor.OutOfRangeWeight)
+	}
+	if cfg.Robust != "" {
+		fmt.Printf("Robust %s fit, %d suspected outlier(s)\n", cfg.Robust, len(outliers))
+	}
+	if points[0].Uncertainty != 0 || cfg.Weighting == models.WeightRange || cfg.Robust != "" {
+		fmt.Println()
+	}
 
-	if err != nil {
-		log.Fatal(err)
+	var fit fitResult
+	switch cfg.Sensor {
+	case models.SensorRTD:
+		fit = fitRTD(cfg, points)
+	case models.SensorPTC:
+		fit = fitPTC(cfg, points)
+	default:
+		fit = fitNTC(cfg, points)
 	}
 
-	fmt.Printf("Steinhart-Hart coefficients:\na = %.3g\nb = %.3g\nc = %.3g\n\n", coeff[0], coeff[1], coeff[2])
+	if cfg.Command == commandOptimise {
+		if err := runOptimise(cfg, fit.model); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if cfg.Dissipation != 0 {
+		worst, worstTemp, worstPower := thermistor.WorstSelfHeating(cfg, fit.model)
+		fmt.Printf("\nSelf-heating (dissipation constant %.3g mW/K)\n", cfg.Dissipation)
+		fmt.Printf("Worst case offset between %.1f and %.1f °C: %.3g K at %.1f °C (%.3g mW)\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, worst, worstTemp, worstPower*1000)
+
+		if cfg.SelfHeating {
+			fit.model = thermistor.SelfHeatingModel(cfg, fit.model)
+			fmt.Println("LUT and headers corrected for self-heating")
+		}
+	}
 
-	fullTable, maxDev, avgDev := thermistor.CheckDeviation(points, coeff)
+	var analysis ccode.Analysis
+	if cfg.RSource != 0 || cfg.RInput != 0 || cfg.Leakage != 0 {
+		worst, worstTemp := thermistor.InputLoadError(cfg, fit.model)
+		analysis.InputLoadError = worst
+		fmt.Printf("\nADC input load (source %.3gk, input %.3gk, leakage %.3g nA)\n", cfg.RSource, cfg.RInput, cfg.Leakage)
+		fmt.Printf("Largest correction between %.1f and %.1f °C: %.3g K at %.1f °C, included in the LUT\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, worst, worstTemp)
+	}
 
-	fmt.Printf("Steinhart-Hart deviation from csv\n")
-	fmt.Printf("Max Deviation: %.3g K, Avg Deviation: %.3g K\n", maxDev, avgDev)
+	if cfg.ADCOffset != 0 || cfg.ADCGain != 0 || len(cfg.INL) != 0 {
+		worst, worstTemp := thermistor.ADCErrorCorrection(cfg, fit.model)
+		analysis.ADCCorrection = worst
+		fmt.Printf("\nADC errors (offset %.3g LSB, gain %.3g ppm, %d INL points)\n", cfg.ADCOffset, cfg.ADCGain, len(cfg.INL))
+		fmt.Printf("Largest correction between %.1f and %.1f °C: %.3g K at %.1f °C, included in the LUT\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, worst, worstTemp)
+	}
 
 	baseName := models.DetermineBaseName(cfg, metadata)
 
+	if cfg.Command == commandInverse {
+		if err := runInverse(cfg, baseName, fit.model); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if cfg.Command == commandLinearise {
+		if err := runLinearise(cfg, baseName, fit.model, metadata, analysis); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if cfg.LUTAuto {
+		cfg.LUTSize, err = selectLUTSize(cfg, fit.model)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if cfg.LUTSize != 0 {
-		tempLUT, resistanceLUT, adcLUT, err = thermistor.GenerateLUT(cfg, coeff)
+		tempLUT, resistanceLUT, adcLUT, err = thermistor.GenerateModelLUT(cfg, fit.model)
 	}
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	files, err := ccode.GenerateOutputs(cfg, baseName, coeff, tempLUT, resistanceLUT, adcLUT, fullTable, metadata)
+	if cfg.Command == commandMonteCarlo {
+		if err := runMonteCarlo(cfg, baseName, fit.model, tempLUT); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var minTempLUT, maxTempLUT []float64
+	if cfg.TolR25 != 0 || cfg.TolB != 0 || cfg.TolRS != 0 {
+		minTempLUT, maxTempLUT, err = thermistor.ToleranceBounds(cfg, fit.model, adcLUT)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		below, above := thermistor.ToleranceBand(cfg, tempLUT, minTempLUT, maxTempLUT)
+		analysis.ToleranceBand = [2]float64{below, above}
+
+		fmt.Printf("\nTolerance analysis (R25 ±%.2g%%, B ±%.2g%%, series resistor ±%.2g%%)\n", cfg.TolR25, cfg.TolB, cfg.TolRS)
+		fmt.Printf("Worst case error between %.1f and %.1f °C: -%.3g K / +%.3g K\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, below, above)
+		if cfg.TolB != 0 && cfg.Sensor != models.SensorNTC {
+			log.Printf("Warning: B tolerance only applies to NTC thermistors and was ignored.")
+		}
+	}
+
+	var powerLUT []float64
+	if cfg.Dissipation != 0 {
+		powerLUT = make([]float64, len(resistanceLUT))
+		for i, r := range resistanceLUT {
+			powerLUT[i] = thermistor.SelfHeatingPower(cfg, r)
+		}
+	}
+
+	files, err := ccode.GenerateOutputs(cfg, baseName, ccode.Outputs{
+		Coeff:         fit.coeff,
+		Beta:          fit.beta,
+		CVD:           fit.cvd,
+		PTC:           fit.ptc,
+		Segments:      fit.segments,
+		TempLUT:       tempLUT,
+		ResistanceLUT: resistanceLUT,
+		ADCLUT:        adcLUT,
+		PowerLUT:      powerLUT,
+		MinTempLUT:    minTempLUT,
+		MaxTempLUT:    maxTempLUT,
+		FullTable:     fit.fullTable,
+		Metadata:      metadata,
+		Stats:         fit.stats,
+		Analysis:      analysis,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -141,6 +694,4 @@ func main() {
 		fmt.Printf("  %s: %s\n", key, path)
 	}
 	fmt.Println()
-
-
 }
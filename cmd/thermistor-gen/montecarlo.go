@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Eriosies/thermistor-lut-gen/internal/csvparser"
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"github.com/Eriosies/thermistor-lut-gen/pkg/thermistor"
+)
+
+const histogramBins int = 50
+
+// runMonteCarlo prints the temperature error statistics of the simulated boards at each
+// temperature and writes them, with a histogram of all errors, to CSV.
+func runMonteCarlo(cfg models.Config, baseName string, model thermistor.Model, lut []float64) error {
+	temps := tabulationTemps(cfg)
+
+	result, err := thermistor.MonteCarlo(cfg, model, lut, temps)
+	if err != nil {
+		return err
+	}
+
+	source := "model"
+	if len(lut) != 0 {
+		source = fmt.Sprintf("%d entry LUT", len(lut))
+	}
+
+	fmt.Printf("\nMonte Carlo: %d trials through the %s\n", cfg.Trials, source)
+	fmt.Printf("R25 ±%.2g%%, B ±%.2g%%, RS ±%.2g%%, RP ±%.2g%% (3σ), Vref mismatch ±%.2g%% (3σ), noise %.2g LSB rms, INL ±%.2g LSB\n",
+		cfg.TolR25, cfg.TolB, cfg.TolRS, cfg.TolRP, cfg.VrefTol, cfg.ADCNoise, cfg.ADCINL)
+
+	fmt.Printf("\n%-12s %-12s %-12s %-12s %-12s\n", "Temp (°C)", "Mean (K)", "Sigma (K)", "99.7% (K)", "Max (K)")
+
+	var rows [][]string
+	var all []float64
+	for i, t := range temps {
+		s := thermistor.ErrorStatistics(result.Errors[i])
+		all = append(all, result.Errors[i]...)
+
+		fmt.Printf("%-12.2f %-12.4f %-12.4f %-12.4f %-12.4f\n", t, s.Mean, s.Sigma, s.P997, s.Max)
+		rows = append(rows, []string{
+			fmt.Sprintf("%.2f", t),
+			fmt.Sprintf("%.4f", s.Mean),
+			fmt.Sprintf("%.4f", s.Sigma),
+			fmt.Sprintf("%.4f", s.P997),
+			fmt.Sprintf("%.4f", s.Max),
+		})
+	}
+
+	s := thermistor.ErrorStatistics(all)
+	fmt.Printf("%-12s %-12.4f %-12.4f %-12.4f %-12.4f\n", "All", s.Mean, s.Sigma, s.P997, s.Max)
+
+	statsCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_montecarlo.csv", baseName))
+	if err := csvparser.WriteCSV(statsCSV, "Temperature (°C),Mean Error (K),Sigma (K),99.7% Abs Error (K),Max Abs Error (K)", rows); err != nil {
+		return err
+	}
+
+	edges, counts := thermistor.ErrorHistogram(all, histogramBins)
+	var histRows [][]string
+	for i, c := range counts {
+		histRows = append(histRows, []string{
+			fmt.Sprintf("%.4f", edges[i]),
+			fmt.Sprintf("%.4f", edges[i+1]),
+			fmt.Sprintf("%.0f", c),
+		})
+	}
+
+	histCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_histogram.csv", baseName))
+	if err := csvparser.WriteCSV(histCSV, "Error From (K),Error To (K),Count", histRows); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nMonte Carlo statistics: %s\nError histogram: %s\n\n", statsCSV, histCSV)
+	return nil
+}
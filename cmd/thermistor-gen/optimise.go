@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"github.com/Eriosies/thermistor-lut-gen/pkg/thermistor"
+)
+
+// runOptimise prints the best divider resistors for reading the model between the
+// temperature limits.
+func runOptimise(cfg models.Config, model thermistor.Model) error {
+	candidates, err := thermistor.OptimiseDivider(cfg, model, cfg.OptMetric, cfg.ESeries, cfg.OptimiseRP, cfg.Top)
+	if err != nil {
+		return err
+	}
+
+	source := "model"
+	if cfg.LUTSize != 0 {
+		source = fmt.Sprintf("%d entry LUT", cfg.LUTSize)
+	}
+
+	fmt.Printf("\nDivider optimisation between %.1f and %.1f °C, %d bit ADC, read through the %s\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, cfg.ADCResolution, source)
+	fmt.Printf("Ranked by %s, resistors from %s\n", cfg.OptMetric, cfg.ESeries)
+
+	fmt.Printf("\n%-12s %-12s %-14s %-14s %-16s %-10s\n", "RS (kΩ)", "RP (kΩ)", "Min LSB/°C", "Worst °C/LSB", "Quantisation (K)", "Codes")
+	for _, c := range candidates {
+		rp := "-"
+		if c.RP != 0 {
+			rp = strconv.FormatFloat(c.RP/1000, 'f', -1, 64)
+		}
+		fmt.Printf("%-12s %-12s %-14.3f %-14.4f %-16.4f %-10.0f\n", strconv.FormatFloat(c.RS/1000, 'f', -1, 64), rp, c.MinSensitivity, c.WorstStep, c.Quantisation, c.CodeSpan)
+	}
+	fmt.Println()
+	return nil
+}
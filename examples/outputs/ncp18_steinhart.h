@@ -66,7 +66,9 @@ __attribute__((always_inline)) static inline float ncp18_steinhart_get_resistanc
 	r = NCP18_STEINHART_RSERIES * v / (NCP18_STEINHART_VREF - v);
 
 #if NCP18_STEINHART_USE_PARALLEL
-	r = 1/((1/r)-(1/NCP18_STEINHART_USE_PARALLEL));
+	if(r >= NCP18_STEINHART_PSERIES)
+		return NCP18_STEINHART_RMAX;
+	r = 1/((1/r)-(1/NCP18_STEINHART_PSERIES));
 #endif
 
 	return r;
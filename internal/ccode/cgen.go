@@ -2,6 +2,7 @@ package ccode
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
@@ -23,7 +24,7 @@ func trimToFileName(path string) string {
 	return fileNameNoExt
 }
 
-func printHeader(w *bufio.Writer, name string, metadata [][2]string, cfg models.Config) {
+func printHeader(w *bufio.Writer, name string, metadata [][2]string, cfg models.Config, analysis Analysis) {
 	now := time.Now()
 
 	fmt.Fprintf(w, "/**\n")
@@ -44,11 +45,45 @@ func printHeader(w *bufio.Writer, name string, metadata [][2]string, cfg models.
 	fmt.Fprintf(w, "\t*\tLUT Size - %d\n", cfg.LUTSize)
 	fmt.Fprintf(w, "\t*\tADC Resolution - %d\n", cfg.ADCResolution)
 	fmt.Fprintf(w, "\t*\tReference voltage - %.2f\n", cfg.VoltageRef)
+	if separateSupply(cfg) {
+		fmt.Fprintf(w, "\t*\tSupply voltage - %.2f, not ratiometric\n", cfg.VoltageSupply)
+	}
+	if cfg.RuntimeVref {
+		fmt.Fprintf(w, "\t*\tReference measured at runtime - _vref functions, VREF is the nominal value\n")
+	}
 	fmt.Fprintf(w, "\t*\tSeries Resistor - %.0f\n", cfg.RS*1000)
 	fmt.Fprintf(w, "\t*\tParallel Resistor - %.0f\n", cfg.RP*1000)
+	switch cfg.Topology {
+	case models.TopologyHigh:
+		fmt.Fprintf(w, "\t*\tTopology - thermistor on the high side, series resistor to ground\n")
+	case models.TopologyBridge:
+		fmt.Fprintf(w, "\t*\tTopology - bridge, reference leg %.0f / %.0f, amplifier gain %g, offset %gV\n", cfg.BridgeTop*1000, cfg.BridgeBottom*1000, cfg.AmpGain, cfg.AmpOffset)
+	case models.TopologyCurrent:
+		if cfg.RRef != 0 {
+			fmt.Fprintf(w, "\t*\tTopology - current source %guA, ratiometric reference resistor %.0f\n", cfg.Excitation, cfg.RRef*1000)
+		} else {
+			fmt.Fprintf(w, "\t*\tTopology - current source %guA\n", cfg.Excitation)
+		}
+	}
 	fmt.Fprintf(w, "\t*\tFixed Point - %ddp\n", cfg.FixedPoint)
 	fmt.Fprintf(w, "\t*\tUpper temperature limit - %.1f\n", cfg.UpperLimitTemp)
 	fmt.Fprintf(w, "\t*\tLower temperature limit - %.1f\n", cfg.LowerLimitTemp)
+	if cfg.Exact {
+		fmt.Fprintf(w, "\t*\tCoefficients - exact three-point solution, not a least squares fit\n")
+	}
+	if cfg.TolR25 != 0 || cfg.TolB != 0 || cfg.TolRS != 0 {
+		fmt.Fprintf(w, "\t*\tTolerances - R25 %.2g%%, B %.2g%%, Series Resistor %.2g%%\n", cfg.TolR25, cfg.TolB, cfg.TolRS)
+		fmt.Fprintf(w, "\t*\tWorst case temperature error - -%.2f K / +%.2f K\n", analysis.ToleranceBand[0], analysis.ToleranceBand[1])
+	}
+	if cfg.RSource != 0 || cfg.RInput != 0 || cfg.Leakage != 0 {
+		fmt.Fprintf(w, "\t*\tADC input load - source %.0f, input %.0f, leakage %gnA, up to %.3g K, corrected in the LUT only\n", cfg.RSource*1000, cfg.RInput*1000, cfg.Leakage, analysis.InputLoadError)
+	}
+	if cfg.ADCOffset != 0 || cfg.ADCGain != 0 || len(cfg.INL) != 0 {
+		fmt.Fprintf(w, "\t*\tADC errors - offset %gLSB, gain %gppm, %d INL points, up to %.3g K, corrected in the LUT only\n", cfg.ADCOffset, cfg.ADCGain, len(cfg.INL), analysis.ADCCorrection)
+	}
+	if cfg.SelfHeating {
+		fmt.Fprintf(w, "\t*\tSelf-heating corrected - dissipation constant %.3g mW/K\n", cfg.Dissipation)
+	}
 	fmt.Fprintf(w, "\t*\n")
 	fmt.Fprintf(w, "\t******************************************************************************\n")
 
@@ -56,31 +91,114 @@ func printHeader(w *bufio.Writer, name string, metadata [][2]string, cfg models.
 
 }
 
-func GenerateSteinhartCcode(path string, coeff [3]float64, metadata [][2]string, cfg models.Config) error {
-	var useParallel int
-	var adcType string
-
-	name := trimToFileName(path)
-	nameUpper := strings.ToUpper(name)
-	nameLower := strings.ToLower(name)
-
+// adcTypeString returns the C type of a raw ADC reading, signed for the bridge's differential ADC.
+func adcTypeString(cfg models.Config) string {
+	var t string
 	switch {
 	case cfg.ADCResolution > 16:
-		adcType = "uint32_t"
+		t = "int32_t"
 	case cfg.ADCResolution > 8:
-		adcType = "uint16_t"
+		t = "int16_t"
 	default:
-		adcType = "uint8_t"
+		t = "int8_t"
+	}
+
+	if cfg.Topology == models.TopologyBridge {
+		return t
+	}
+	return "u" + t
+}
+
+// separateSupply reports whether the divider or bridge is supplied from other than the ADC
+// reference, so the generated C needs its own supply voltage.
+func separateSupply(cfg models.Config) bool {
+	return cfg.Topology != models.TopologyCurrent && !models.Ratiometric(cfg)
+}
+
+// supplyDefine returns the C expression of the divider or bridge supply voltage.
+func supplyDefine(name string, cfg models.Config) string {
+	if separateSupply(cfg) {
+		return fmt.Sprintf("%s_VSUPPLY", strings.ToUpper(name))
 	}
+	return vrefDefine(name, cfg)
+}
+
+// vrefDefine returns the C expression of the ADC reference inside the conversion functions: the
+// measured vref parameter with cfg.RuntimeVref, else the VREF define.
+func vrefDefine(name string, cfg models.Config) string {
+	if cfg.RuntimeVref {
+		return "vref"
+	}
+	return fmt.Sprintf("%s_VREF", strings.ToUpper(name))
+}
+
+// vrefSuffix, vrefParam and vrefArg return the function name suffix, extra parameter and extra
+// argument of the conversion functions that take the measured ADC reference.
+func vrefSuffix(cfg models.Config) string {
+	if cfg.RuntimeVref {
+		return "_vref"
+	}
+	return ""
+}
+
+func vrefParam(cfg models.Config) string {
+	if cfg.RuntimeVref {
+		return ", float vref"
+	}
+	return ""
+}
+
+func vrefArg(cfg models.Config) string {
+	if cfg.RuntimeVref {
+		return ", vref"
+	}
+	return ""
+}
+
+// printVrefFunctions writes <name>_get_resistance on the constant reference and the helpers that
+// turn a measured supply into the vref of the _vref functions: from millivolts, or from the
+// VREFINT reading and its factory calibration value.
+func printVrefFunctions(w *bufio.Writer, name string, cfg models.Config) {
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	nameCalVref := fmt.Sprintf("%s_VREFINT_CAL_VREF", nameUpper)
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_resistance(%s adcValue)\n", nameLower, adcTypeString(cfg))
+	fmt.Fprintf(w, "{\n\treturn %s_get_resistance_vref(adcValue, %s_VREF);\n}\n\n", nameLower, nameUpper)
+
+	fmt.Fprintf(w, "/* ADC reference (V) the VREFINT calibration value was taken at */\n")
+	fmt.Fprintf(w, "#define %s %ff\n\n", nameCalVref, cfg.VrefintCal)
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_vref_from_mv(uint32_t mv)\n", nameLower)
+	fmt.Fprintf(w, "{\n\treturn (float) mv / 1000.0f;\n}\n\n")
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_vref_from_vrefint(uint16_t vrefint, uint16_t vrefintCal)\n", nameLower)
+	fmt.Fprintf(w, "{\n\tif(vrefint == 0)\n\t\treturn 0;\n")
+	fmt.Fprintf(w, "\treturn %s * (float) vrefintCal / (float) vrefint;\n}\n\n", nameCalVref)
+}
+
+// printTempWrapper writes <name>_get_temp on the constant reference after <name>_get_temp_vref.
+func printTempWrapper(w *bufio.Writer, name string, cfg models.Config) {
+	if !cfg.RuntimeVref {
+		return
+	}
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp(%s adcValue)\n", strings.ToLower(name), adcTypeString(cfg))
+	fmt.Fprintf(w, "{\n\treturn %s_get_temp_vref(adcValue, %s_VREF);\n}\n\n", strings.ToLower(name), strings.ToUpper(name))
+}
+
+// printResistanceFunction writes the divider defines and the <name>_get_resistance function.
+func printResistanceFunction(w *bufio.Writer, name string, cfg models.Config) {
+	var useParallel int
+
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	adcType := adcTypeString(cfg)
 
 	if cfg.RP != 0.0 {
 		useParallel = 1
 	}
 
 	nameUseParallel := fmt.Sprintf("%s_USE_PARALLEL", nameUpper)
-	nameCoeffA := fmt.Sprintf("%s_COEFF_A", nameUpper)
-	nameCoeffB := fmt.Sprintf("%s_COEFF_B", nameUpper)
-	nameCoeffC := fmt.Sprintf("%s_COEFF_C", nameUpper)
 	nameVRef := fmt.Sprintf("%s_VREF", nameUpper)
 	nameADCRes := fmt.Sprintf("%s_ADC_RESOLUTION", nameUpper)
 	nameADCMax := fmt.Sprintf("%s_ADC_MAX", nameUpper)
@@ -89,6 +207,197 @@ func GenerateSteinhartCcode(path string, coeff [3]float64, metadata [][2]string,
 	nameRMax := fmt.Sprintf("%s_RMAX", nameUpper)
 	nameRMin := fmt.Sprintf("%s_RMIN", nameUpper)
 
+	fmt.Fprintf(w, "#define %s %ff\n", nameVRef, cfg.VoltageRef)
+	nameSupply := supplyDefine(name, cfg)
+	if separateSupply(cfg) {
+		fmt.Fprintf(w, "#define %s %ff\n", nameSupply, cfg.VoltageSupply)
+	}
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "#define %s %d\n", nameADCRes, cfg.ADCResolution)
+	fmt.Fprintf(w, "#define %s ((1 << %s) - 1)\n\n", nameADCMax, nameADCRes)
+
+	fmt.Fprintf(w, "#define %s %ff\n", nameRSeries, cfg.RS*1000)
+	if useParallel != 0 {
+		fmt.Fprintf(w, "#define %s %ff\n", namePSeries, cfg.RP*1000)
+	}
+
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "#define %s %.3Ef\n", nameRMax, resistanceMax)
+	fmt.Fprintf(w, "#define %s %.3Ef\n", nameRMin, resistanceMin)
+
+	switch cfg.Topology {
+	case models.TopologyBridge:
+		printBridgeFunction(w, name, cfg)
+	case models.TopologyCurrent:
+		printCurrentFunction(w, name, cfg)
+	}
+
+	fmt.Fprintf(w, "\n__attribute__((always_inline)) static inline float %s_get_resistance%s(%s adcValue%s)\n", nameLower, vrefSuffix(cfg), adcType, vrefParam(cfg))
+	fmt.Fprintf(w, "{\n\tfloat r, v;\n\n")
+	if cfg.Topology == models.TopologyBridge {
+		nameADCHalf := fmt.Sprintf("%s_ADC_HALF", nameUpper)
+		fmt.Fprintf(w, "\tif(adcValue == -%s)\n\t\treturn %s;\n", nameADCHalf, nameRMin)
+		fmt.Fprintf(w, "\tif(adcValue == %s - 1)\n\t\treturn %s;\n\n", nameADCHalf, nameRMax)
+		fmt.Fprintf(w, "\tv = %s_bridge_voltage(adcValue%s);\n", nameLower, vrefArg(cfg))
+		fmt.Fprintf(w, "\tif(v <= 0)\n\t\treturn %s;\n", nameRMin)
+		fmt.Fprintf(w, "\tif(v >= %s)\n\t\treturn %s;\n\n", nameSupply, nameRMax)
+		fmt.Fprintf(w, "\tr = %s * v / (%s - v);\n\n", nameRSeries, nameSupply)
+	} else if cfg.Topology == models.TopologyCurrent {
+		fmt.Fprintf(w, "\tif(adcValue == 0)\n\t\treturn %s;\n", nameRMin)
+		fmt.Fprintf(w, "\tif(adcValue == %s)\n\t\treturn %s;\n\n", nameADCMax, nameRMax)
+		fmt.Fprintf(w, "\tv = %s_sense_voltage(adcValue%s);\n", nameLower, vrefArg(cfg))
+		fmt.Fprintf(w, "\tr = v / %s_IEXC;\n\n", nameUpper)
+	} else if cfg.Topology == models.TopologyHigh {
+		// The thermistor is the top of the divider, so the voltage rises as its resistance falls.
+		fmt.Fprintf(w, "\tif(adcValue == 0)\n\t\treturn %s;\n", nameRMax)
+		fmt.Fprintf(w, "\tif(adcValue == %s)\n\t\treturn %s;\n\n", nameADCMax, nameRMin)
+		fmt.Fprintf(w, "\tv = %s * (float) adcValue / %s;\n", vrefDefine(name, cfg), nameADCMax)
+		if separateSupply(cfg) {
+			fmt.Fprintf(w, "\tif(v >= %s)\n\t\treturn %s;\n", nameSupply, nameRMin)
+		}
+		fmt.Fprintf(w, "\tr = %s * (%s - v) / v;\n\n", nameRSeries, nameSupply)
+	} else {
+		fmt.Fprintf(w, "\tif(adcValue == 0)\n\t\treturn %s;\n", nameRMin)
+		fmt.Fprintf(w, "\tif(adcValue == %s)\n\t\treturn %s;\n\n", nameADCMax, nameRMax)
+		fmt.Fprintf(w, "\tv = %s * (float) adcValue / %s;\n", vrefDefine(name, cfg), nameADCMax)
+		if separateSupply(cfg) {
+			// With a supply below the reference the top codes are out of reach.
+			fmt.Fprintf(w, "\tif(v >= %s)\n\t\treturn %s;\n", nameSupply, nameRMax)
+		}
+		fmt.Fprintf(w, "\tr = %s * v / (%s - v);\n\n", nameRSeries, nameSupply)
+	}
+
+	// The parallel resistor caps the network, so a higher reading is out of range.
+	fmt.Fprintf(w, "#if %s\n", nameUseParallel)
+	fmt.Fprintf(w, "\tif(r >= %s)\n\t\treturn %s;\n", namePSeries, nameRMax)
+	fmt.Fprintf(w, "\tr = 1/((1/r)-(1/%s));\n", namePSeries)
+	fmt.Fprintf(w, "#endif\n\n")
+
+	fmt.Fprintf(w, "\treturn r;\n")
+	fmt.Fprintf(w, "}\n\n")
+
+	if cfg.RuntimeVref {
+		printVrefFunctions(w, name, cfg)
+	}
+
+	if cfg.SelfHeating {
+		printSelfHeatingFunction(w, name, cfg)
+	}
+}
+
+// printBridgeFunction writes the bridge defines and <name>_bridge_voltage, the voltage (V) across
+// the thermistor leg recovered from the signed differential reading.
+func printBridgeFunction(w *bufio.Writer, name string, cfg models.Config) {
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+
+	nameADCHalf := fmt.Sprintf("%s_ADC_HALF", nameUpper)
+	nameBridgeTop := fmt.Sprintf("%s_BRIDGE_TOP", nameUpper)
+	nameBridgeBottom := fmt.Sprintf("%s_BRIDGE_BOTTOM", nameUpper)
+	nameAmpGain := fmt.Sprintf("%s_AMP_GAIN", nameUpper)
+	nameAmpOffset := fmt.Sprintf("%s_AMP_OFFSET", nameUpper)
+
+	fmt.Fprintf(w, "\n#define %s (1 << (%s_ADC_RESOLUTION - 1))\n", nameADCHalf, nameUpper)
+	fmt.Fprintf(w, "#define %s %ff\n", nameBridgeTop, cfg.BridgeTop*1000)
+	fmt.Fprintf(w, "#define %s %ff\n", nameBridgeBottom, cfg.BridgeBottom*1000)
+	fmt.Fprintf(w, "#define %s %ff\n", nameAmpGain, cfg.AmpGain)
+	fmt.Fprintf(w, "#define %s %ff\n", nameAmpOffset, cfg.AmpOffset)
+
+	fmt.Fprintf(w, "\n__attribute__((always_inline)) static inline float %s_bridge_voltage(%s adcValue%s)\n", nameLower, adcTypeString(cfg), vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "\tfloat vIn = (%s * (float) adcValue / %s - %s) / %s;\n", vrefDefine(name, cfg), nameADCHalf, nameAmpOffset, nameAmpGain)
+	fmt.Fprintf(w, "\treturn vIn + %s * %s / (%s + %s);\n", supplyDefine(name, cfg), nameBridgeBottom, nameBridgeTop, nameBridgeBottom)
+	fmt.Fprintf(w, "}\n")
+}
+
+// printCurrentFunction writes the current source defines and <name>_sense_voltage, the voltage
+// (V) the excitation current develops across the thermistor. With a reference resistor the ADC
+// reference is its drop, so the reading is independent of the excitation current.
+func printCurrentFunction(w *bufio.Writer, name string, cfg models.Config) {
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+
+	nameIExc := fmt.Sprintf("%s_IEXC", nameUpper)
+	nameRRef := fmt.Sprintf("%s_RREF", nameUpper)
+
+	fmt.Fprintf(w, "\n/* Excitation current (A) */\n")
+	fmt.Fprintf(w, "#define %s %ef\n", nameIExc, cfg.Excitation*1e-6)
+	if cfg.RRef != 0 {
+		fmt.Fprintf(w, "#define %s %ff\n", nameRRef, cfg.RRef*1000)
+	}
+
+	fmt.Fprintf(w, "\n__attribute__((always_inline)) static inline float %s_sense_voltage(%s adcValue%s)\n", nameLower, adcTypeString(cfg), vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	if cfg.RRef != 0 {
+		if cfg.RuntimeVref {
+			// The reference resistor sets the ADC reference, so the supply does not matter.
+			fmt.Fprintf(w, "\t(void) vref;\n")
+		}
+		fmt.Fprintf(w, "\treturn %s * %s * (float) adcValue / %s_ADC_MAX;\n", nameIExc, nameRRef, nameUpper)
+	} else {
+		fmt.Fprintf(w, "\treturn %s * (float) adcValue / %s_ADC_MAX;\n", vrefDefine(name, cfg), nameUpper)
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+// printSelfHeatingFunction writes <name>_self_heating, the rise (K) of the thermistor above
+// ambient from the power dissipated in it at adcValue. On the high side the voltage across the
+// thermistor is the remainder of the supply.
+func printSelfHeatingFunction(w *bufio.Writer, name string, cfg models.Config) {
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	nameDissipation := fmt.Sprintf("%s_DISSIPATION", nameUpper)
+
+	fmt.Fprintf(w, "/* Dissipation constant (W/K) */\n")
+	fmt.Fprintf(w, "#define %s %ef\n\n", nameDissipation, cfg.Dissipation/1000)
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_self_heating%s(%s adcValue%s)\n", nameLower, vrefSuffix(cfg), adcTypeString(cfg), vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	if cfg.Topology == models.TopologyBridge {
+		fmt.Fprintf(w, "\tfloat v = %s_bridge_voltage(adcValue%s);\n", nameLower, vrefArg(cfg))
+	} else if cfg.Topology == models.TopologyCurrent {
+		fmt.Fprintf(w, "\tfloat v = %s_sense_voltage(adcValue%s);\n", nameLower, vrefArg(cfg))
+	} else if cfg.Topology == models.TopologyHigh {
+		fmt.Fprintf(w, "\tfloat v = %s - %s * (float) adcValue / %s_ADC_MAX;\n", supplyDefine(name, cfg), vrefDefine(name, cfg), nameUpper)
+	} else {
+		fmt.Fprintf(w, "\tfloat v = %s * (float) adcValue / %s_ADC_MAX;\n", vrefDefine(name, cfg), nameUpper)
+	}
+	fmt.Fprintf(w, "\treturn v * v / %s_get_resistance%s(adcValue%s) / %s;\n", nameLower, vrefSuffix(cfg), vrefArg(cfg), nameDissipation)
+	fmt.Fprintf(w, "}\n\n")
+}
+
+// selfHeatingTerm returns the C subtracted from _get_temp to correct for self-heating.
+func selfHeatingTerm(name string, cfg models.Config) string {
+	if !cfg.SelfHeating {
+		return ""
+	}
+	return fmt.Sprintf(" - %s_self_heating%s(adcValue%s)", strings.ToLower(name), vrefSuffix(cfg), vrefArg(cfg))
+}
+
+// GenerateSteinhartCcode writes the Steinhart-Hart header. coeff is dense by power of
+// ln(R), so {a, b, 0, c} is the classic equation and zero terms are left out.
+func GenerateSteinhartCcode(path string, coeff []float64, metadata [][2]string, cfg models.Config, analysis Analysis) error {
+	return GenerateSegmentedCcode(path, []models.Segment{{Coeff: coeff}}, metadata, cfg, analysis)
+}
+
+// GenerateSegmentedCcode writes a Steinhart-Hart header whose _get_temp picks the
+// coefficient set of each band by comparing the resistance against the band thresholds.
+func GenerateSegmentedCcode(path string, segments []models.Segment, metadata [][2]string, cfg models.Config, analysis Analysis) error {
+	var useParallel int
+
+	name := trimToFileName(path)
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	adcType := adcTypeString(cfg)
+
+	if cfg.RP != 0.0 {
+		useParallel = 1
+	}
+
+	nameUseParallel := fmt.Sprintf("%s_USE_PARALLEL", nameUpper)
+
 	f, err := os.Create(path)
 	if err != nil {
 		return err
@@ -97,7 +406,7 @@ func GenerateSteinhartCcode(path string, coeff [3]float64, metadata [][2]string,
 
 	w := bufio.NewWriter(f)
 
-	printHeader(w, name, metadata, cfg)
+	printHeader(w, name, metadata, cfg, analysis)
 
 	fmt.Fprintf(w, "#ifndef %s_H\n", nameUpper)
 	fmt.Fprintf(w, "#define %s_H\n\n", nameUpper)
@@ -105,45 +414,163 @@ func GenerateSteinhartCcode(path string, coeff [3]float64, metadata [][2]string,
 
 	fmt.Fprintf(w, "#define %s %d\n\n", nameUseParallel, useParallel)
 
-	fmt.Fprintf(w, "#define %s %ef\n", nameCoeffA, coeff[0])
-	fmt.Fprintf(w, "#define %s %ef\n", nameCoeffB, coeff[1])
-	fmt.Fprintf(w, "#define %s %ef\n\n", nameCoeffC, coeff[2])
+	exprs := make([]string, len(segments))
+	thresholds := make([]string, len(segments))
+	for i, seg := range segments {
+		prefix := nameUpper
+		if len(segments) > 1 {
+			prefix = fmt.Sprintf("%s_SEG%d", nameUpper, i)
+			fmt.Fprintf(w, "/* %s */\n", bandDescription(seg))
+		}
+		exprs[i] = printPolynomialCoefficients(w, prefix, seg.Coeff)
+
+		if i < len(segments)-1 {
+			thresholds[i] = fmt.Sprintf("%s_THRESHOLD", prefix)
+			fmt.Fprintf(w, "#define %s %ff\n", thresholds[i], seg.Threshold)
+		}
+		fmt.Fprintf(w, "\n")
+	}
 
 	fmt.Fprintf(w, "#define KELVIN_TO_CELSIUS 273.15f\n\n")
 
-	fmt.Fprintf(w, "#define %s %ff\n\n", nameVRef, cfg.VoltageRef)
+	printResistanceFunction(w, name, cfg)
 
-	fmt.Fprintf(w, "#define %s %d\n", nameADCRes, cfg.ADCResolution)
-	fmt.Fprintf(w, "#define %s ((1 << %s) - 1)\n\n", nameADCMax, nameADCRes)
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp%s(%s adcValue%s)\n", nameLower, vrefSuffix(cfg), adcType, vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	if len(segments) == 1 {
+		fmt.Fprintf(w, "\tfloat lnR = logf(%s_get_resistance%s(adcValue%s));\n", nameLower, vrefSuffix(cfg), vrefArg(cfg))
+		fmt.Fprintf(w, "\treturn 1 / (%s) - KELVIN_TO_CELSIUS%s;\n", exprs[0], selfHeatingTerm(name, cfg))
+	} else {
+		fmt.Fprintf(w, "\tfloat r = %s_get_resistance%s(adcValue%s);\n", nameLower, vrefSuffix(cfg), vrefArg(cfg))
+		fmt.Fprintf(w, "\tfloat lnR = logf(r);\n\n")
+		for i := 0; i < len(segments)-1; i++ {
+			fmt.Fprintf(w, "\tif(r >= %s)\n", thresholds[i])
+			fmt.Fprintf(w, "\t\treturn 1 / (%s) - KELVIN_TO_CELSIUS%s;\n", exprs[i], selfHeatingTerm(name, cfg))
+		}
+		fmt.Fprintf(w, "\treturn 1 / (%s) - KELVIN_TO_CELSIUS%s;\n", exprs[len(segments)-1], selfHeatingTerm(name, cfg))
+	}
+	fmt.Fprintf(w, "}\n\n")
+	printTempWrapper(w, name, cfg)
 
-	fmt.Fprintf(w, "#define %s %ff\n", nameRSeries, cfg.RS*1000)
-	if useParallel != 0 {
-		fmt.Fprintf(w, "#define %s %ff\n", namePSeries, cfg.RP*1000)
+	fmt.Fprintf(w, "#endif")
+
+	return w.Flush()
+}
+
+// printPolynomialCoefficients defines <prefix>_COEFF_A, _B, ... for the non-zero terms of
+// the dense ln(R) polynomial and returns the C expression summing them.
+func printPolynomialCoefficients(w *bufio.Writer, prefix string, coeff []float64) string {
+	var terms []string
+
+	for power, c := range coeff {
+		if c == 0 {
+			continue
+		}
+		coeffName := fmt.Sprintf("%s_COEFF_%c", prefix, 'A'+len(terms))
+		fmt.Fprintf(w, "#define %s %ef\n", coeffName, c)
+		terms = append(terms, coeffName+strings.Repeat(" * lnR", power))
 	}
 
-	fmt.Fprintf(w, "\n")
+	return strings.Join(terms, " + ")
+}
 
-	fmt.Fprintf(w, "#define %s %.3Ef\n", nameRMax, resistanceMax)
-	fmt.Fprintf(w, "#define %s %.3Ef\n", nameRMin, resistanceMin)
+func bandDescription(seg models.Segment) string {
+	switch {
+	case math.IsInf(seg.Lower, -1):
+		return fmt.Sprintf("Band below %.1f C", seg.Upper)
+	case math.IsInf(seg.Upper, 1):
+		return fmt.Sprintf("Band above %.1f C", seg.Lower)
+	default:
+		return fmt.Sprintf("Band %.1f C to %.1f C", seg.Lower, seg.Upper)
+	}
+}
 
-	fmt.Fprintf(w, "\n__attribute__((always_inline)) static inline float %s_get_resistance(%s adcValue)\n", nameLower, adcType)
-	fmt.Fprintf(w, "{\n\tfloat r, v;\n\n")
-	fmt.Fprintf(w, "\tif(adcValue == 0)\n\t\treturn %s;\n", nameRMin)
-	fmt.Fprintf(w, "\tif(adcValue == %s)\n\t\treturn %s;\n\n", nameADCMax, nameRMax)
-	fmt.Fprintf(w, "\tv = %s * (float) adcValue / %s;\n", nameVRef, nameADCMax)
-	fmt.Fprintf(w, "\tr = %s * v / (%s - v);\n\n", nameRSeries, nameVRef)
+func GenerateBetaCcode(path string, beta [2]float64, metadata [][2]string, cfg models.Config, analysis Analysis) error {
+	var useParallel int
 
-	fmt.Fprintf(w, "#if %s\n", nameUseParallel)
-	fmt.Fprintf(w, "\tr = 1/((1/r)-(1/%s));\n", nameUseParallel)
-	fmt.Fprintf(w, "#endif\n\n")
+	name := trimToFileName(path)
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	adcType := adcTypeString(cfg)
 
-	fmt.Fprintf(w, "\treturn r;\n")
+	if cfg.RP != 0.0 {
+		useParallel = 1
+	}
+
+	nameUseParallel := fmt.Sprintf("%s_USE_PARALLEL", nameUpper)
+	nameR25 := fmt.Sprintf("%s_R25", nameUpper)
+	nameB := fmt.Sprintf("%s_B", nameUpper)
+	nameT0 := fmt.Sprintf("%s_T0", nameUpper)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	printHeader(w, name, metadata, cfg, analysis)
+
+	fmt.Fprintf(w, "#ifndef %s_H\n", nameUpper)
+	fmt.Fprintf(w, "#define %s_H\n\n", nameUpper)
+	fmt.Fprintf(w, "#include \"stdint.h\"\n#include \"math.h\"\n\n")
+
+	fmt.Fprintf(w, "#define %s %d\n\n", nameUseParallel, useParallel)
+
+	fmt.Fprintf(w, "#define %s %ff\n", nameR25, beta[0])
+	fmt.Fprintf(w, "#define %s %ff\n", nameB, beta[1])
+	fmt.Fprintf(w, "#define %s %ff\n\n", nameT0, models.BetaReferenceTemp+models.KelvinToCelsius)
+
+	fmt.Fprintf(w, "#define KELVIN_TO_CELSIUS 273.15f\n\n")
+
+	printResistanceFunction(w, name, cfg)
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp%s(%s adcValue%s)\n", nameLower, vrefSuffix(cfg), adcType, vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "\tfloat lnR = logf(%s_get_resistance%s(adcValue%s) / %s);\n", nameLower, vrefSuffix(cfg), vrefArg(cfg), nameR25)
+	fmt.Fprintf(w, "\treturn 1 / (1 / %s + lnR / %s) - KELVIN_TO_CELSIUS%s;\n", nameT0, nameB, selfHeatingTerm(name, cfg))
 	fmt.Fprintf(w, "}\n\n")
+	printTempWrapper(w, name, cfg)
+
+	fmt.Fprintf(w, "#endif")
+
+	return w.Flush()
+}
+
+// GenerateLinearCcode writes a header whose _get_temp reads the ADC code as a straight line,
+// temperature (°C) = gain·adc + offset, for a divider linearised over the temperature limits.
+// maxError (K) is the deviation of the line from the fitted model.
+func GenerateLinearCcode(path string, gain, offset, maxError float64, metadata [][2]string, cfg models.Config, analysis Analysis) error {
+	name := trimToFileName(path)
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	adcType := adcTypeString(cfg)
+
+	nameGain := fmt.Sprintf("%s_GAIN", nameUpper)
+	nameOffset := fmt.Sprintf("%s_OFFSET", nameUpper)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	printHeader(w, name, metadata, cfg, analysis)
+
+	fmt.Fprintf(w, "#ifndef %s_H\n", nameUpper)
+	fmt.Fprintf(w, "#define %s_H\n\n", nameUpper)
+	fmt.Fprintf(w, "#include \"stdint.h\"\n\n")
+
+	fmt.Fprintf(w, "/* Linear between %.1f C and %.1f C, max error %.3f K before ADC quantisation */\n", cfg.LowerLimitTemp, cfg.UpperLimitTemp, maxError)
+	fmt.Fprintf(w, "#define %s %ef\n", nameGain, gain)
+	fmt.Fprintf(w, "#define %s %ff\n\n", nameOffset, offset)
 
 	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp(%s adcValue)\n", nameLower, adcType)
 	fmt.Fprintf(w, "{\n")
-	fmt.Fprintf(w, "\tfloat lnR = logf(%s_get_resistance(adcValue));\n", nameLower)
-	fmt.Fprintf(w, "\treturn 1 / (%s + %s * lnR + %s * lnR * lnR * lnR) - KELVIN_TO_CELSIUS;\n", nameCoeffA, nameCoeffB, nameCoeffC)
+	fmt.Fprintf(w, "\treturn %s * (float) adcValue + %s;\n", nameGain, nameOffset)
 	fmt.Fprintf(w, "}\n\n")
 
 	fmt.Fprintf(w, "#endif")
@@ -151,7 +578,193 @@ func GenerateSteinhartCcode(path string, coeff [3]float64, metadata [][2]string,
 	return w.Flush()
 }
 
-func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg models.Config) error {
+// GenerateCVDCcode writes the RTD header for cvd = {R0, A, B, C}. _get_temp solves the
+// Callendar-Van Dusen quadratic, or the linear equation when B is 0, refined by Newton
+// iterations below 0 °C where C applies.
+func GenerateCVDCcode(path string, cvd [4]float64, metadata [][2]string, cfg models.Config, analysis Analysis) error {
+	var useParallel int
+
+	name := trimToFileName(path)
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	adcType := adcTypeString(cfg)
+
+	if cfg.RP != 0.0 {
+		useParallel = 1
+	}
+
+	nameUseParallel := fmt.Sprintf("%s_USE_PARALLEL", nameUpper)
+	nameR0 := fmt.Sprintf("%s_R0", nameUpper)
+	nameA := fmt.Sprintf("%s_A", nameUpper)
+	nameB := fmt.Sprintf("%s_B", nameUpper)
+	nameC := fmt.Sprintf("%s_C", nameUpper)
+	nameIterations := fmt.Sprintf("%s_ITERATIONS", nameUpper)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	printHeader(w, name, metadata, cfg, analysis)
+
+	fmt.Fprintf(w, "#ifndef %s_H\n", nameUpper)
+	fmt.Fprintf(w, "#define %s_H\n\n", nameUpper)
+	fmt.Fprintf(w, "#include \"stdint.h\"\n#include \"math.h\"\n\n")
+
+	fmt.Fprintf(w, "#define %s %d\n\n", nameUseParallel, useParallel)
+
+	fmt.Fprintf(w, "#define %s %ff\n", nameR0, cvd[0])
+	fmt.Fprintf(w, "#define %s %ef\n", nameA, cvd[1])
+	fmt.Fprintf(w, "#define %s %ef\n", nameB, cvd[2])
+	fmt.Fprintf(w, "#define %s %ef\n", nameC, cvd[3])
+	fmt.Fprintf(w, "#define %s 3\n\n", nameIterations)
+
+	printResistanceFunction(w, name, cfg)
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp%s(%s adcValue%s)\n", nameLower, vrefSuffix(cfg), adcType, vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "\tfloat ratio = %s_get_resistance%s(adcValue%s) / %s;\n", nameLower, vrefSuffix(cfg), vrefArg(cfg), nameR0)
+	if cvd[2] == 0 {
+		// Without B the equation above 0 °C is linear.
+		fmt.Fprintf(w, "\tfloat t;\n\n")
+		fmt.Fprintf(w, "\tt = (ratio - 1) / %s;\n\n", nameA)
+	} else {
+		fmt.Fprintf(w, "\tfloat disc = %s * %s - 4 * %s * (1 - ratio);\n", nameA, nameA, nameB)
+		fmt.Fprintf(w, "\tfloat t;\n\n")
+		fmt.Fprintf(w, "\tif(disc < 0)\n\t\tdisc = 0;\n")
+		fmt.Fprintf(w, "\tt = (-%s + sqrtf(disc)) / (2 * %s);\n\n", nameA, nameB)
+	}
+	fmt.Fprintf(w, "#if %s != 0\n", nameIterations)
+	fmt.Fprintf(w, "\tif(ratio < 1)\n\t{\n")
+	fmt.Fprintf(w, "\t\tfor(int i = 0; i < %s; i++)\n\t\t{\n", nameIterations)
+	fmt.Fprintf(w, "\t\t\tfloat f = 1 + %s * t + %s * t * t + %s * (t - 100) * t * t * t - ratio;\n", nameA, nameB, nameC)
+	fmt.Fprintf(w, "\t\t\tfloat df = %s + 2 * %s * t + %s * (4 * t * t * t - 300 * t * t);\n", nameA, nameB, nameC)
+	fmt.Fprintf(w, "\t\t\tt -= f / df;\n")
+	fmt.Fprintf(w, "\t\t}\n\t}\n")
+	fmt.Fprintf(w, "#endif\n\n")
+	fmt.Fprintf(w, "\treturn t%s;\n", selfHeatingTerm(name, cfg))
+	fmt.Fprintf(w, "}\n\n")
+	printTempWrapper(w, name, cfg)
+
+	fmt.Fprintf(w, "#endif")
+
+	return w.Flush()
+}
+
+// GeneratePTCCcode writes the silicon PTC header for the dense polynomial coeff in powers
+// of (T - models.PTCReferenceTemp). _get_temp solves the quadratic on the rising branch, with
+// Newton iterations for higher orders.
+func GeneratePTCCcode(path string, coeff []float64, metadata [][2]string, cfg models.Config, analysis Analysis) error {
+	var useParallel int
+
+	name := trimToFileName(path)
+	nameUpper := strings.ToUpper(name)
+	nameLower := strings.ToLower(name)
+	adcType := adcTypeString(cfg)
+
+	if cfg.RP != 0.0 {
+		useParallel = 1
+	}
+
+	nameUseParallel := fmt.Sprintf("%s_USE_PARALLEL", nameUpper)
+	nameT0 := fmt.Sprintf("%s_T0", nameUpper)
+	nameIterations := fmt.Sprintf("%s_ITERATIONS", nameUpper)
+
+	coeffNames := make([]string, len(coeff))
+	for k := range coeff {
+		coeffNames[k] = fmt.Sprintf("%s_COEFF_%d", nameUpper, k)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	printHeader(w, name, metadata, cfg, analysis)
+
+	fmt.Fprintf(w, "#ifndef %s_H\n", nameUpper)
+	fmt.Fprintf(w, "#define %s_H\n\n", nameUpper)
+	fmt.Fprintf(w, "#include \"stdint.h\"\n#include \"math.h\"\n\n")
+
+	fmt.Fprintf(w, "#define %s %d\n\n", nameUseParallel, useParallel)
+
+	fmt.Fprintf(w, "/* R = COEFF_0 + COEFF_1 * (T - T0) + COEFF_2 * (T - T0)^2 + ... */\n")
+	fmt.Fprintf(w, "#define %s %ff\n", nameT0, models.PTCReferenceTemp)
+	for k, c := range coeff {
+		fmt.Fprintf(w, "#define %s %ef\n", coeffNames[k], c)
+	}
+	if len(coeff) > 3 {
+		fmt.Fprintf(w, "#define %s 3\n", nameIterations)
+	}
+	fmt.Fprintf(w, "\n")
+
+	printResistanceFunction(w, name, cfg)
+
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp%s(%s adcValue%s)\n", nameLower, vrefSuffix(cfg), adcType, vrefParam(cfg))
+	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "\tfloat r = %s_get_resistance%s(adcValue%s);\n", nameLower, vrefSuffix(cfg), vrefArg(cfg))
+
+	if len(coeff) < 3 {
+		fmt.Fprintf(w, "\treturn (r - %s) / %s + %s%s;\n", coeffNames[0], coeffNames[1], nameT0, selfHeatingTerm(name, cfg))
+		fmt.Fprintf(w, "}\n\n")
+		printTempWrapper(w, name, cfg)
+		fmt.Fprintf(w, "#endif")
+		return w.Flush()
+	}
+
+	// The conjugate form of the quadratic root keeps float precision for a small COEFF_2 and
+	// holds when it is 0.
+	fmt.Fprintf(w, "\tfloat disc = %s * %s + 4 * %s * (r - %s);\n", coeffNames[1], coeffNames[1], coeffNames[2], coeffNames[0])
+	fmt.Fprintf(w, "\tfloat x;\n\n")
+	fmt.Fprintf(w, "\tif(disc < 0)\n\t\tdisc = 0;\n")
+	fmt.Fprintf(w, "\tx = 2 * (r - %s) / (%s + sqrtf(disc));\n\n", coeffNames[0], coeffNames[1])
+
+	if len(coeff) > 3 {
+		fmt.Fprintf(w, "\tfor(int i = 0; i < %s; i++)\n\t{\n", nameIterations)
+		fmt.Fprintf(w, "\t\tfloat f = %s, df = 0;\n\n", coeffNames[len(coeff)-1])
+		for k := len(coeff) - 2; k >= 0; k-- {
+			fmt.Fprintf(w, "\t\tdf = df * x + f;\n")
+			fmt.Fprintf(w, "\t\tf = f * x + %s;\n", coeffNames[k])
+		}
+		fmt.Fprintf(w, "\t\tx -= (f - r) / df;\n")
+		fmt.Fprintf(w, "\t}\n\n")
+	}
+
+	fmt.Fprintf(w, "\treturn x + %s%s;\n", nameT0, selfHeatingTerm(name, cfg))
+	fmt.Fprintf(w, "}\n\n")
+	printTempWrapper(w, name, cfg)
+
+	fmt.Fprintf(w, "#endif")
+
+	return w.Flush()
+}
+
+// LUTIntType returns the C type and its size in bytes of the integer LUT for lutTemp
+// stored with fixedPoint decimal places.
+func LUTIntType(lutTemp []float64, fixedPoint uint) (string, int) {
+	scale := math.Pow(10, float64(fixedPoint))
+	minTemp, maxTemp := lutTemp[0]*scale, lutTemp[0]*scale
+	for _, t := range lutTemp {
+		minTemp = math.Min(minTemp, t*scale)
+		maxTemp = math.Max(maxTemp, t*scale)
+	}
+
+	switch {
+	case maxTemp < math.MaxInt8 && minTemp > math.MinInt8:
+		return "int8_t", 1
+	case maxTemp < math.MaxInt16 && minTemp > math.MinInt16:
+		return "int16_t", 2
+	}
+	return "int32_t", 4
+}
+
+func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg models.Config, analysis Analysis) error {
 	if cfg.LUTSize == 0 {
 		return fmt.Errorf("LUT size is 0; cannot generate LUT header")
 	}
@@ -175,12 +788,9 @@ func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg
 
 	w := bufio.NewWriter(f)
 
-	printHeader(w, name, metadata, cfg)
+	printHeader(w, name, metadata, cfg, analysis)
 
-	intTypeString := "int16_t"
-	if lutTemp[cfg.LUTSize-1] < 127 && lutTemp[0] > -128 {
-		intTypeString = "int8_t"
-	}
+	intTypeString, _ := LUTIntType(lutTemp, cfg.FixedPoint)
 
 	fmt.Fprintf(w, "#ifndef %s_H\n", nameUpper)
 	fmt.Fprintf(w, "#define %s_H\n\n", nameUpper)
@@ -193,6 +803,14 @@ func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg
 	fmt.Fprintf(w, "#define %s %dU\n", nameLUTSizeBits, lutSizeBits)
 	fmt.Fprintf(w, "#define %s %dU\n\n\n", nameADCRes, cfg.ADCResolution)
 
+	// Signed bridge readings index the table as offset binary.
+	adcParam, adcIndex := "uint32_t", "adcValue"
+	if cfg.Topology == models.TopologyBridge {
+		nameADCHalf := fmt.Sprintf("%s_ADC_HALF", nameUpper)
+		fmt.Fprintf(w, "#define %s (1 << (%s - 1))\n\n\n", nameADCHalf, nameADCRes)
+		adcParam, adcIndex = "int32_t", fmt.Sprintf("(uint32_t)(adcValue + %s)", nameADCHalf)
+	}
+
 	fmt.Fprintf(w, "#if %s\n\n", nameUseFloat)
 	fmt.Fprintf(w, "static const float %s_float[%s] = {", name, nameLUTSize)
 	for i := 0; i < int(cfg.LUTSize)-1; i++ {
@@ -203,8 +821,8 @@ func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg
 	}
 	fmt.Fprintf(w, "%.2ff };\n\n", lutTemp[cfg.LUTSize-1])
 
-	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp_float(uint32_t adcValue)\n", name)
-	fmt.Fprintf(w, "{\n\tuint32_t index = adcValue >> (%s - %s);\n\treturn %s_float[index];\n}\n\n", nameADCRes, nameLUTSizeBits, name)
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline float %s_get_temp_float(%s adcValue)\n", name, adcParam)
+	fmt.Fprintf(w, "{\n\tuint32_t index = %s >> (%s - %s);\n\treturn %s_float[index];\n}\n\n", adcIndex, nameADCRes, nameLUTSizeBits, name)
 
 	fmt.Fprintf(w, "#endif\n\n")
 
@@ -216,10 +834,10 @@ func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg
 		}
 		fmt.Fprintf(w, "%d, ", int(lutTemp[i]*math.Pow(10, float64(cfg.FixedPoint))))
 	}
-	fmt.Fprintf(w, "%d };\n\n", int(lutTemp[cfg.LUTSize-1]))
+	fmt.Fprintf(w, "%d };\n\n", int(lutTemp[cfg.LUTSize-1]*math.Pow(10, float64(cfg.FixedPoint))))
 
-	fmt.Fprintf(w, "__attribute__((always_inline)) static inline %s %s_get_temp_int(uint32_t adcValue)\n", intTypeString, name)
-	fmt.Fprintf(w, "{\n\tuint32_t index = adcValue >> (%s - %s);\n\treturn %s_int[index];\n}\n\n", nameADCRes, nameLUTSizeBits, name)
+	fmt.Fprintf(w, "__attribute__((always_inline)) static inline %s %s_get_temp_int(%s adcValue)\n", intTypeString, name, adcParam)
+	fmt.Fprintf(w, "{\n\tuint32_t index = %s >> (%s - %s);\n\treturn %s_int[index];\n}\n\n", adcIndex, nameADCRes, nameLUTSizeBits, name)
 
 	fmt.Fprintf(w, "#endif\n\n")
 	fmt.Fprintf(w, "#endif")
@@ -227,16 +845,39 @@ func GenerateLUTCcode(path string, lutTemp []float64, metadata [][2]string, cfg
 	return w.Flush()
 }
 
-func GenerateOutputs(cfg models.Config, baseName string, coeff [3]float64,
-	tempLUT, resistanceLUT []float64, adcLUT []uint,
-	fullTable []models.DeviationTable, metadata [][2]string,
-) (map[string]string, error) {
+// Analysis holds the results of the analyses summarised in the generated header comments.
+type Analysis struct {
+	ToleranceBand  [2]float64 // worst case K below and above nominal, with tolerances
+	InputLoadError float64    // K, largest temperature correction of the input load
+	ADCCorrection  float64    // K, largest temperature correction of the ADC errors
+}
+
+// Outputs holds the fit results and tables written by GenerateOutputs.
+type Outputs struct {
+	Coeff         []float64
+	Beta          [2]float64
+	CVD           [4]float64
+	PTC           []float64
+	Segments      []models.Segment
+	TempLUT       []float64
+	ResistanceLUT []float64
+	ADCLUT        []uint
+	PowerLUT      []float64 // W dissipated in the thermistor, nil = no dissipation constant
+	MinTempLUT    []float64 // tolerance bounds, nil = no tolerance analysis
+	MaxTempLUT    []float64
+	FullTable     []models.DeviationTable
+	Metadata      [][2]string
+	Stats         *models.FitStats
+	Analysis      Analysis
+}
+
+func GenerateOutputs(cfg models.Config, baseName string, out Outputs) (map[string]string, error) {
+	metadata := out.Metadata
 
 	files := make(map[string]string)
 
 	lutCFile := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_lut.h", strings.ToLower(baseName)))
 	steinhartCFile := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_steinhart.h", strings.ToLower(baseName)))
-	files["steinhartC"] = steinhartCFile
 
 	if cfg.LUTSize != 0 {
 		lutCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_LUT.csv", baseName))
@@ -244,32 +885,83 @@ func GenerateOutputs(cfg models.Config, baseName string, coeff [3]float64,
 		files["lutCSV"] = lutCSV
 
 		var lutRows [][]string
-		for i := range tempLUT {
+		for i := range out.TempLUT {
 			lutRows = append(lutRows, []string{
-				fmt.Sprintf("%.3f", resistanceLUT[i]),
-				fmt.Sprintf("%.3f", tempLUT[i]),
-				fmt.Sprintf("%d", adcLUT[i]),
+				fmt.Sprintf("%.3f", out.ResistanceLUT[i]),
+				fmt.Sprintf("%.3f", out.TempLUT[i]),
+				fmt.Sprintf("%d", models.SignedADCValue(cfg, out.ADCLUT[i])),
 			})
+			if out.MinTempLUT != nil {
+				lutRows[i] = append(lutRows[i],
+					fmt.Sprintf("%.3f", out.MinTempLUT[i]),
+					fmt.Sprintf("%.3f", out.MaxTempLUT[i]),
+				)
+			}
+			if out.PowerLUT != nil {
+				lutRows[i] = append(lutRows[i],
+					fmt.Sprintf("%.4f", out.PowerLUT[i]*1000),
+					fmt.Sprintf("%.4f", out.PowerLUT[i]*1000/cfg.Dissipation),
+				)
+			}
+		}
+
+		lutHeader := "Resistance (Ω),Table Temp (°C),ADC Value"
+		if out.MinTempLUT != nil {
+			lutHeader += ",Min Temp (°C),Max Temp (°C)"
+		}
+		if out.PowerLUT != nil {
+			lutHeader += ",Power (mW),Self-heating (K)"
 		}
 
-		if err := csvparser.WriteCSV(lutCSV, "Resistance (Ω),Table Temp (°C),ADC Value", lutRows); err != nil {
+		if err := csvparser.WriteCSV(lutCSV, lutHeader, lutRows); err != nil {
 			return files, err
 		}
 
-		if err := GenerateLUTCcode(lutCFile, tempLUT, metadata, cfg); err != nil {
+		if err := GenerateLUTCcode(lutCFile, out.TempLUT, metadata, cfg, out.Analysis); err != nil {
 			return files, err
 		}
 	}
 
-	if err := GenerateSteinhartCcode(steinhartCFile, coeff, metadata, cfg); err != nil {
-		return files, err
+	if cfg.Sensor == models.SensorRTD {
+		cvdCFile := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_cvd.h", strings.ToLower(baseName)))
+		files["cvdC"] = cvdCFile
+
+		if err := GenerateCVDCcode(cvdCFile, out.CVD, metadata, cfg, out.Analysis); err != nil {
+			return files, err
+		}
+	} else if cfg.Sensor == models.SensorPTC {
+		ptcCFile := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_ptc.h", strings.ToLower(baseName)))
+		files["ptcC"] = ptcCFile
+
+		if err := GeneratePTCCcode(ptcCFile, out.PTC, metadata, cfg, out.Analysis); err != nil {
+			return files, err
+		}
+	} else if len(out.Segments) > 1 {
+		files["steinhartC"] = steinhartCFile
+		if err := GenerateSegmentedCcode(steinhartCFile, out.Segments, metadata, cfg, out.Analysis); err != nil {
+			return files, err
+		}
+	} else {
+		files["steinhartC"] = steinhartCFile
+		if err := GenerateSteinhartCcode(steinhartCFile, out.Coeff, metadata, cfg, out.Analysis); err != nil {
+			return files, err
+		}
+	}
+
+	if cfg.Model == models.ModelBeta {
+		betaCFile := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_beta.h", strings.ToLower(baseName)))
+		files["betaC"] = betaCFile
+
+		if err := GenerateBetaCcode(betaCFile, out.Beta, metadata, cfg, out.Analysis); err != nil {
+			return files, err
+		}
 	}
 
 	varianceCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_Variance.csv", baseName))
 	files["varianceCSV"] = varianceCSV
 
 	var varianceRows [][]string
-	for _, row := range fullTable {
+	for _, row := range out.FullTable {
 		varianceRows = append(varianceRows, []string{
 			fmt.Sprintf("%.3f", row.Resistance),
 			fmt.Sprintf("%.3f", row.TemperatureCSV),
@@ -281,5 +973,35 @@ func GenerateOutputs(cfg models.Config, baseName string, coeff [3]float64,
 		return files, err
 	}
 
+	if out.Stats != nil {
+		statsJSON := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_stats.json", baseName))
+		statsCSV := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s_stats.csv", baseName))
+		files["statsJSON"] = statsJSON
+		files["statsCSV"] = statsCSV
+
+		data, err := json.MarshalIndent(out.Stats, "", "  ")
+		if err != nil {
+			return files, err
+		}
+		if err := os.WriteFile(statsJSON, data, 0644); err != nil {
+			return files, err
+		}
+
+		var bandRows [][]string
+		for _, row := range out.Stats.Band {
+			bandRows = append(bandRows, []string{
+				fmt.Sprintf("%.3f", row.Resistance),
+				fmt.Sprintf("%.3f", row.Temperature),
+				fmt.Sprintf("%.4f", row.Temperature-row.HalfWidth),
+				fmt.Sprintf("%.4f", row.Temperature+row.HalfWidth),
+				fmt.Sprintf("%.4f", row.HalfWidth),
+			})
+		}
+		header := fmt.Sprintf("Resistance (Ω),Fitted Temp (°C),Lower %.0f%% (°C),Upper %.0f%% (°C),Half Width (K)", out.Stats.ConfidenceLevel*100, out.Stats.ConfidenceLevel*100)
+		if err := csvparser.WriteCSV(statsCSV, header, bandRows); err != nil {
+			return files, err
+		}
+	}
+
 	return files, nil
 }
@@ -1,6 +1,7 @@
 package ccode_test
 
 import (
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -26,9 +27,9 @@ func TestGenerateSteinhartCcode(t *testing.T) {
 		UpperLimitTemp: 100,
 		LowerLimitTemp: 0,
 	}
-	coeff := [3]float64{0.001, 0.0001, 0.00001}
+	coeff := []float64{0.001, 0.0001, 0, 0.00001}
 
-	err := ccode.GenerateSteinhartCcode(filePath, coeff, metadata, cfg)
+	err := ccode.GenerateSteinhartCcode(filePath, coeff, metadata, cfg, ccode.Analysis{})
 	if err != nil {
 		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
 	}
@@ -47,6 +48,515 @@ func TestGenerateSteinhartCcode(t *testing.T) {
 	if !strings.Contains(content, "TEST_STEINHART_H") {
 		t.Errorf("generated header guard not found")
 	}
+
+	// The parallel resistor is removed using its value, not the 0/1 enable flag.
+	if !strings.Contains(content, "r = 1/((1/r)-(1/TEST_STEINHART_PSERIES));") {
+		t.Errorf("parallel resistor correction does not divide by TEST_STEINHART_PSERIES")
+	}
+	if !strings.Contains(content, "if(r >= TEST_STEINHART_PSERIES)\n\t\treturn TEST_STEINHART_RMAX;") {
+		t.Errorf("readings beyond the parallel resistor are not returned as TEST_STEINHART_RMAX")
+	}
+
+	if strings.Contains(content, "exact three-point") {
+		t.Errorf("least squares coefficients marked as exact")
+	}
+
+	cfg.Exact = true
+	if err := ccode.GenerateSteinhartCcode(filePath, coeff, metadata, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+
+	data, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	if !strings.Contains(string(data), "exact three-point solution") {
+		t.Errorf("exact coefficients not marked in header comment")
+	}
+
+	cfg.Exact = false
+	cfg.SelfHeating = true
+	cfg.Dissipation = 1.5
+	if err := ccode.GenerateSteinhartCcode(filePath, coeff, metadata, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+
+	data, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	for _, want := range []string{"#define TEST_STEINHART_DISSIPATION 1.500000e-03f", "KELVIN_TO_CELSIUS - test_steinhart_self_heating(adcValue);", "Self-heating corrected"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("self-heating header missing %q", want)
+		}
+	}
+}
+
+func TestGenerateSteinhartCcode_Topology(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_steinhart.h")
+
+	cfg := models.Config{
+		InputFile:     "test.csv",
+		ADCResolution: 12,
+		RS:            10,
+		RP:            100,
+		VoltageRef:    3.3,
+		SelfHeating:   true,
+		Dissipation:   1,
+	}
+	coeff := []float64{0.001, 0.0001, 0, 0.00001}
+
+	tests := []struct {
+		topology string
+		want     []string
+	}{
+		{models.TopologyLow, []string{
+			"if(adcValue == 0)\n\t\treturn TEST_STEINHART_RMIN;",
+			"r = TEST_STEINHART_RSERIES * v / (TEST_STEINHART_VREF - v);",
+			"float v = TEST_STEINHART_VREF * (float) adcValue",
+		}},
+		{models.TopologyHigh, []string{
+			"if(adcValue == 0)\n\t\treturn TEST_STEINHART_RMAX;",
+			"r = TEST_STEINHART_RSERIES * (TEST_STEINHART_VREF - v) / v;",
+			"float v = TEST_STEINHART_VREF - TEST_STEINHART_VREF * (float) adcValue",
+			"Topology - thermistor on the high side",
+		}},
+	}
+
+	for _, tt := range tests {
+		cfg.Topology = tt.topology
+		if err := ccode.GenerateSteinhartCcode(filePath, coeff, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+			t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("failed to read generated file: %v", err)
+		}
+		content := string(data)
+
+		for _, want := range tt.want {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s topology: generated file missing %q", tt.topology, want)
+			}
+		}
+	}
+}
+
+func TestGenerateSteinhartCcode_Polynomial(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_steinhart.h")
+
+	cfg := models.Config{
+		InputFile:     "test.csv",
+		ADCResolution: 12,
+		RS:            10,
+		VoltageRef:    3.3,
+	}
+	coeff := []float64{0.001, 0.0002, 0.000005, 0.00000001}
+
+	err := ccode.GenerateSteinhartCcode(filePath, coeff, [][2]string{}, cfg, ccode.Analysis{})
+	if err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	content := string(data)
+
+	want := "1 / (TEST_STEINHART_COEFF_A + TEST_STEINHART_COEFF_B * lnR + TEST_STEINHART_COEFF_C * lnR * lnR + TEST_STEINHART_COEFF_D * lnR * lnR * lnR)"
+	if !strings.Contains(content, want) {
+		t.Errorf("generated file missing polynomial expression %q", want)
+	}
+}
+
+func TestGenerateSegmentedCcode(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_steinhart.h")
+
+	cfg := models.Config{
+		InputFile:     "test.csv",
+		ADCResolution: 12,
+		RS:            10,
+		VoltageRef:    3.3,
+	}
+	segments := []models.Segment{
+		{Lower: math.Inf(-1), Upper: 25, Threshold: 10000, Coeff: []float64{0.001, 0.0002, 0, 0.0000002}},
+		{Lower: 25, Upper: math.Inf(1), Coeff: []float64{0.0011, 0.00021, 0, 0.0000001}},
+	}
+
+	err := ccode.GenerateSegmentedCcode(filePath, segments, [][2]string{}, cfg, ccode.Analysis{})
+	if err != nil {
+		t.Fatalf("GenerateSegmentedCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	content := string(data)
+
+	for _, want := range []string{
+		"#define TEST_STEINHART_SEG0_COEFF_A",
+		"#define TEST_STEINHART_SEG1_COEFF_C",
+		"#define TEST_STEINHART_SEG0_THRESHOLD 10000.000000f",
+		"if(r >= TEST_STEINHART_SEG0_THRESHOLD)",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+
+	if strings.Contains(content, "SEG1_THRESHOLD") {
+		t.Errorf("last band should not have a threshold")
+	}
+}
+
+func TestGenerateBetaCcode(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_beta.h")
+
+	cfg := models.Config{
+		InputFile:     "test.csv",
+		ADCResolution: 12,
+		RS:            10,
+		VoltageRef:    3.3,
+	}
+
+	err := ccode.GenerateBetaCcode(filePath, [2]float64{10000, 3950}, [][2]string{}, cfg, ccode.Analysis{})
+	if err != nil {
+		t.Fatalf("GenerateBetaCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	content := string(data)
+
+	for _, want := range []string{"#define TEST_BETA_R25 10000.000000f", "#define TEST_BETA_B 3950.000000f", "test_beta_get_resistance", "test_beta_get_temp"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+}
+
+func TestGenerateCVDCcode(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_cvd.h")
+
+	cfg := models.Config{
+		InputFile:     "test.csv",
+		ADCResolution: 12,
+		RS:            1,
+		VoltageRef:    3.3,
+		Sensor:        models.SensorRTD,
+	}
+
+	err := ccode.GenerateCVDCcode(filePath, [4]float64{1000, 3.9083e-3, -5.775e-7, -4.183e-12}, [][2]string{}, cfg, ccode.Analysis{})
+	if err != nil {
+		t.Fatalf("GenerateCVDCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	content := string(data)
+
+	for _, want := range []string{"#define TEST_CVD_R0 1000.000000f", "#define TEST_CVD_A 3.908300e-03f", "#define TEST_CVD_C -4.183000e-12f", "test_cvd_get_resistance", "test_cvd_get_temp"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+
+	// A linear RTD has no quadratic to solve.
+	if err := ccode.GenerateCVDCcode(filePath, [4]float64{1000, 3.85e-3, 0, 0}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateCVDCcode returned error: %v", err)
+	}
+
+	data, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	content = string(data)
+	if !strings.Contains(content, "t = (ratio - 1) / TEST_CVD_A;") || strings.Contains(content, "sqrtf") {
+		t.Errorf("linear CVD header does not solve t = (ratio - 1) / A")
+	}
+}
+
+func TestGenerateSteinhartCcode_Bridge(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_steinhart.h")
+	lutPath := filepath.Join(tmpDir, "test_lut.h")
+
+	cfg := models.Config{
+		InputFile:      "test.csv",
+		ADCResolution:  16,
+		RS:             10,
+		VoltageRef:     2.5,
+		Topology:       models.TopologyBridge,
+		BridgeTop:      10,
+		BridgeBottom:   10,
+		AmpGain:        8,
+		AmpOffset:      0.01,
+		LUTSize:        4,
+		UpperLimitTemp: 50,
+		LowerLimitTemp: 0,
+	}
+
+	if err := ccode.GenerateSteinhartCcode(filePath, []float64{0.001, 0.0001, 0, 0.00001}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+	if err := ccode.GenerateLUTCcode(lutPath, []float64{50, 30, 20, 0}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateLUTCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+	lut, err := os.ReadFile(lutPath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	for _, want := range []string{
+		"#define TEST_STEINHART_AMP_GAIN 8.000000f",
+		"#define TEST_STEINHART_AMP_OFFSET 0.010000f",
+		"test_steinhart_bridge_voltage(int16_t adcValue)",
+		"test_steinhart_get_temp(int16_t adcValue)",
+		"if(adcValue == -TEST_STEINHART_ADC_HALF)",
+		"Topology - bridge",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+
+	for _, want := range []string{"test_lut_get_temp_float(int32_t adcValue)", "(uint32_t)(adcValue + TEST_LUT_ADC_HALF) >>"} {
+		if !strings.Contains(string(lut), want) {
+			t.Errorf("generated LUT missing %q", want)
+		}
+	}
+}
+
+func TestGenerateSteinhartCcode_Current(t *testing.T) {
+	for _, rref := range []float64{0, 20} {
+		filePath := filepath.Join(t.TempDir(), "test_steinhart.h")
+
+		cfg := models.Config{
+			InputFile:      "test.csv",
+			ADCResolution:  12,
+			VoltageRef:     3.3,
+			Topology:       models.TopologyCurrent,
+			Excitation:     100,
+			RRef:           rref,
+			UpperLimitTemp: 80,
+			LowerLimitTemp: 0,
+		}
+
+		if err := ccode.GenerateSteinhartCcode(filePath, []float64{0.001, 0.0001, 0, 0.00001}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+			t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("failed to read generated file: %v", err)
+		}
+
+		want := []string{
+			"#define TEST_STEINHART_IEXC 1.000000e-04f",
+			"test_steinhart_sense_voltage(uint16_t adcValue)",
+			"r = v / TEST_STEINHART_IEXC;",
+			"Topology - current source 100uA",
+		}
+		if rref != 0 {
+			want = append(want, "#define TEST_STEINHART_RREF 20000.000000f", "return TEST_STEINHART_IEXC * TEST_STEINHART_RREF * (float) adcValue")
+		} else {
+			want = append(want, "return TEST_STEINHART_VREF * (float) adcValue")
+		}
+		for _, w := range want {
+			if !strings.Contains(string(data), w) {
+				t.Errorf("rref %g: generated file missing %q", rref, w)
+			}
+		}
+	}
+}
+
+func TestGenerateSteinhartCcode_SeparateSupply(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "test_steinhart.h")
+
+	cfg := models.Config{
+		InputFile:      "test.csv",
+		ADCResolution:  12,
+		RS:             10,
+		VoltageRef:     2.5,
+		VoltageSupply:  3.3,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+	}
+
+	if err := ccode.GenerateSteinhartCcode(filePath, []float64{0.001, 0.0001, 0, 0.00001}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	for _, want := range []string{
+		"#define TEST_STEINHART_VSUPPLY 3.300000f",
+		"Supply voltage - 3.30, not ratiometric",
+		"r = TEST_STEINHART_RSERIES * v / (TEST_STEINHART_VSUPPLY - v);",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+
+	// A supply equal to the reference is ratiometric and needs no separate define.
+	cfg.VoltageSupply = cfg.VoltageRef
+	if err := ccode.GenerateSteinhartCcode(filePath, []float64{0.001, 0.0001, 0, 0.00001}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+	if data, _ = os.ReadFile(filePath); strings.Contains(string(data), "VSUPPLY") {
+		t.Error("ratiometric divider should not define VSUPPLY")
+	}
+}
+
+func TestGenerateSteinhartCcode_RuntimeVref(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "test_steinhart.h")
+
+	cfg := models.Config{
+		InputFile:      "test.csv",
+		ADCResolution:  12,
+		RS:             10,
+		VoltageRef:     3.3,
+		RuntimeVref:    true,
+		VrefintCal:     3.0,
+		SelfHeating:    true,
+		Dissipation:    2,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+	}
+
+	if err := ccode.GenerateSteinhartCcode(filePath, []float64{0.001, 0.0001, 0, 0.00001}, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateSteinhartCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	for _, want := range []string{
+		"test_steinhart_get_resistance_vref(uint16_t adcValue, float vref)",
+		"v = vref * (float) adcValue / TEST_STEINHART_ADC_MAX;",
+		"r = TEST_STEINHART_RSERIES * v / (vref - v);",
+		"return test_steinhart_get_resistance_vref(adcValue, TEST_STEINHART_VREF);",
+		"test_steinhart_get_temp_vref(uint16_t adcValue, float vref)",
+		"return test_steinhart_get_temp_vref(adcValue, TEST_STEINHART_VREF);",
+		"test_steinhart_self_heating_vref(adcValue, vref)",
+		"#define TEST_STEINHART_VREFINT_CAL_VREF 3.000000f",
+		"test_steinhart_vref_from_vrefint(uint16_t vrefint, uint16_t vrefintCal)",
+		"\tif(vrefint == 0)\n\t\treturn 0;\n\treturn TEST_STEINHART_VREFINT_CAL_VREF * (float) vrefintCal / (float) vrefint;",
+		"test_steinhart_vref_from_mv(uint32_t mv)",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+}
+
+func TestGenerateLinearCcode(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_linear.h")
+
+	cfg := models.Config{
+		InputFile:      "test.csv",
+		ADCResolution:  12,
+		RS:             3.24,
+		VoltageRef:     3.3,
+		LowerLimitTemp: 0,
+		UpperLimitTemp: 50,
+	}
+
+	if err := ccode.GenerateLinearCcode(filePath, -0.0341, 128.7, 0.42, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+		t.Fatalf("GenerateLinearCcode returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+
+	content := string(data)
+
+	for _, want := range []string{"#define TEST_LINEAR_GAIN -3.410000e-02f", "#define TEST_LINEAR_OFFSET 128.700000f", "max error 0.420 K", "test_linear_get_temp(uint16_t adcValue)"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated file missing %q", want)
+		}
+	}
+	if strings.Contains(content, "logf") {
+		t.Errorf("linear header should not need math.h functions")
+	}
+}
+
+func TestGeneratePTCCcode(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test_ptc.h")
+
+	cfg := models.Config{
+		InputFile:     "test.csv",
+		ADCResolution: 12,
+		RS:            2.7,
+		VoltageRef:    3.3,
+		Sensor:        models.SensorPTC,
+	}
+
+	tests := []struct {
+		coeff []float64
+		want  []string
+		avoid string
+	}{
+		{[]float64{2000, 15.76}, []string{"(r - TEST_PTC_COEFF_0) / TEST_PTC_COEFF_1 + TEST_PTC_T0"}, "sqrtf"},
+		{[]float64{2000, 15.76, 0.03874}, []string{"#define TEST_PTC_COEFF_2 3.874000e-02f", "x = 2 * (r - TEST_PTC_COEFF_0) / (TEST_PTC_COEFF_1 + sqrtf(disc));"}, "ITERATIONS"},
+		{[]float64{2000, 15.76, 0}, []string{"x = 2 * (r - TEST_PTC_COEFF_0) / (TEST_PTC_COEFF_1 + sqrtf(disc));"}, "/ (2 * TEST_PTC_COEFF_2)"},
+		{[]float64{2000, 15.7, 0.04, -1e-5}, []string{"#define TEST_PTC_ITERATIONS", "f = f * x + TEST_PTC_COEFF_0;"}, ""},
+	}
+
+	for _, tt := range tests {
+		if err := ccode.GeneratePTCCcode(filePath, tt.coeff, [][2]string{}, cfg, ccode.Analysis{}); err != nil {
+			t.Fatalf("GeneratePTCCcode returned error: %v", err)
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("failed to read generated file: %v", err)
+		}
+
+		content := string(data)
+		for _, want := range append(tt.want, "#define TEST_PTC_T0 25.000000f", "test_ptc_get_temp") {
+			if !strings.Contains(content, want) {
+				t.Errorf("order %d: generated file missing %q", len(tt.coeff)-1, want)
+			}
+		}
+		if tt.avoid != "" && strings.Contains(content, tt.avoid) {
+			t.Errorf("order %d: generated file should not contain %q", len(tt.coeff)-1, tt.avoid)
+		}
+	}
 }
 
 func TestGenerateLUTCcode(t *testing.T) {
@@ -62,7 +572,7 @@ func TestGenerateLUTCcode(t *testing.T) {
 	}
 	lutTemp := []float64{0, 25, 50, 75}
 
-	err := ccode.GenerateLUTCcode(filePath, lutTemp, metadata, cfg)
+	err := ccode.GenerateLUTCcode(filePath, lutTemp, metadata, cfg, ccode.Analysis{})
 	if err != nil {
 		t.Fatalf("GenerateLUTCcode returned error: %v", err)
 	}
@@ -79,9 +589,39 @@ func TestGenerateLUTCcode(t *testing.T) {
 	if !strings.Contains(content, "#define TEST_LUT_SIZE") {
 		t.Errorf("LUT size define not found")
 	}
+	if !strings.Contains(content, "static const int16_t test_lut_int") {
+		t.Errorf("int LUT should be int16_t once scaled by the fixed point")
+	}
+	if !strings.Contains(content, "5000, 7500 };") {
+		t.Errorf("last int LUT entry not scaled by the fixed point")
+	}
 
 }
 
+func TestLUTIntType(t *testing.T) {
+	tests := []struct {
+		lut        []float64
+		fixedPoint uint
+		wantType   string
+		wantBytes  int
+	}{
+		{[]float64{-40, 125}, 0, "int8_t", 1},
+		{[]float64{-40, 125.5}, 0, "int8_t", 1},
+		{[]float64{-40, 130}, 0, "int16_t", 2},
+		{[]float64{-12.5, 12.5}, 1, "int8_t", 1},
+		{[]float64{-12.5, 13}, 1, "int16_t", 2},
+		{[]float64{-40, 125}, 2, "int16_t", 2},
+		{[]float64{-40, 400}, 2, "int32_t", 4},
+	}
+
+	for _, tt := range tests {
+		gotType, gotBytes := ccode.LUTIntType(tt.lut, tt.fixedPoint)
+		if gotType != tt.wantType || gotBytes != tt.wantBytes {
+			t.Errorf("LUTIntType(%v, %d) = %s, %d; want %s, %d", tt.lut, tt.fixedPoint, gotType, gotBytes, tt.wantType, tt.wantBytes)
+		}
+	}
+}
+
 func TestGenerateOutputs(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := models.Config{
@@ -97,16 +637,18 @@ func TestGenerateOutputs(t *testing.T) {
 		LowerLimitTemp: 0,
 	}
 	baseName := "test"
-	coeff := [3]float64{0.001, 0.0001, 0.00001}
-	tempLUT := []float64{0, 50}
-	resistanceLUT := []float64{10000, 5000}
-	adcLUT := []uint{0, 4095}
-	fullTable := []models.DeviationTable{
-		{Resistance: 10000, TemperatureCSV: 0, TemperatureCalc: 0, Deviation: 0},
+	out := ccode.Outputs{
+		Coeff:         []float64{0.001, 0.0001, 0, 0.00001},
+		TempLUT:       []float64{0, 50},
+		ResistanceLUT: []float64{10000, 5000},
+		ADCLUT:        []uint{0, 4095},
+		FullTable: []models.DeviationTable{
+			{Resistance: 10000, TemperatureCSV: 0, TemperatureCalc: 0, Deviation: 0},
+		},
+		Metadata: [][2]string{{"Manufacturer", "TestCorp"}},
 	}
-	metadata := [][2]string{{"Manufacturer", "TestCorp"}}
 
-	files, err := ccode.GenerateOutputs(cfg, baseName, coeff, tempLUT, resistanceLUT, adcLUT, fullTable, metadata)
+	files, err := ccode.GenerateOutputs(cfg, baseName, out)
 	if err != nil {
 		t.Fatalf("GenerateOutputs returned error: %v", err)
 	}
@@ -116,6 +658,101 @@ func TestGenerateOutputs(t *testing.T) {
 			t.Errorf("expected file %s to exist, got error: %v", path, err)
 		}
 	}
+
+	if _, ok := files["betaC"]; ok {
+		t.Errorf("beta header generated without beta model selected")
+	}
+
+	cfg.Model = models.ModelBeta
+	out.Beta = [2]float64{10000, 3950}
+	files, err = ccode.GenerateOutputs(cfg, baseName, out)
+	if err != nil {
+		t.Fatalf("GenerateOutputs returned error: %v", err)
+	}
+
+	if _, err := os.Stat(files["betaC"]); err != nil {
+		t.Errorf("expected beta header to exist, got error: %v", err)
+	}
+
+	shCfg := cfg
+	shCfg.Dissipation = 2
+	shCfg.TolR25 = 1
+	shCfg.RSource = 1
+	shCfg.ADCOffset = 2
+	shOut := out
+	shOut.Analysis.ToleranceBand = [2]float64{0.25, 0.3}
+	shOut.Analysis.InputLoadError = 0.12
+	shOut.Analysis.ADCCorrection = 0.08
+	shOut.PowerLUT = []float64{0, 0.0002}
+	shOut.MinTempLUT = []float64{0, 49.75}
+	shOut.MaxTempLUT = []float64{0, 50.3}
+	files, err = ccode.GenerateOutputs(shCfg, baseName, shOut)
+	if err != nil {
+		t.Fatalf("GenerateOutputs returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(files["lutCSV"])
+	if err != nil {
+		t.Fatalf("failed to read LUT CSV: %v", err)
+	}
+	if !strings.Contains(string(data), "Min Temp (°C),Max Temp (°C),Power (mW),Self-heating (K)") || !strings.Contains(string(data), "50.000,4095,49.750,50.300,0.2000,0.1000") {
+		t.Errorf("unexpected LUT CSV content:\n%s", data)
+	}
+
+	data, err = os.ReadFile(files["lutC"])
+	if err != nil {
+		t.Fatalf("failed to read LUT header: %v", err)
+	}
+	if !strings.Contains(string(data), "Worst case temperature error - -0.25 K / +0.30 K") {
+		t.Errorf("LUT header missing tolerance summary")
+	}
+	if !strings.Contains(string(data), "leakage 0nA, up to 0.12 K, corrected in the LUT only") {
+		t.Errorf("LUT header missing input load summary")
+	}
+	if !strings.Contains(string(data), "0 INL points, up to 0.08 K, corrected in the LUT only") {
+		t.Errorf("LUT header missing ADC error summary")
+	}
+
+	rtdCfg := cfg
+	rtdCfg.Sensor = models.SensorRTD
+	rtdOut := out
+	rtdOut.CVD = [4]float64{1000, 3.9083e-3, -5.775e-7, 0}
+	files, err = ccode.GenerateOutputs(rtdCfg, baseName, rtdOut)
+	if err != nil {
+		t.Fatalf("GenerateOutputs returned error: %v", err)
+	}
+
+	if _, err := os.Stat(files["cvdC"]); err != nil {
+		t.Errorf("expected CVD header to exist, got error: %v", err)
+	}
+	if _, ok := files["steinhartC"]; ok {
+		t.Errorf("Steinhart header generated for an RTD")
+	}
+
+	out.Stats = &models.FitStats{
+		Points:          4,
+		DOF:             1,
+		ConfidenceLevel: 0.95,
+		Band:            []models.ConfidencePoint{{Resistance: 10000, Temperature: 25, HalfWidth: 0.1}},
+	}
+	files, err = ccode.GenerateOutputs(cfg, baseName, out)
+	if err != nil {
+		t.Fatalf("GenerateOutputs returned error: %v", err)
+	}
+
+	for _, key := range []string{"statsJSON", "statsCSV"} {
+		if _, err := os.Stat(files[key]); err != nil {
+			t.Errorf("expected %s to exist, got error: %v", key, err)
+		}
+	}
+
+	data, err = os.ReadFile(files["statsCSV"])
+	if err != nil {
+		t.Fatalf("failed to read stats CSV: %v", err)
+	}
+	if !strings.Contains(string(data), "10000.000,25.000,24.9000,25.1000,0.1000") {
+		t.Errorf("unexpected stats CSV content:\n%s", data)
+	}
 }
 
 func extractFloatArray(content, arrayName string) ([]float64, error) {
@@ -161,7 +798,7 @@ func TestLUTValues(t *testing.T) {
 		FixedPoint:    2,
 	}
 
-	err := ccode.GenerateLUTCcode(filePath, lutTemp, [][2]string{}, cfg)
+	err := ccode.GenerateLUTCcode(filePath, lutTemp, [][2]string{}, cfg, ccode.Analysis{})
 	if err != nil {
 		t.Fatalf("GenerateLUTCcode returned error: %v", err)
 	}
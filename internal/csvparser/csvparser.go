@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -14,39 +15,26 @@ const maxCSVSize int64 = 10 * 1024 * 1024 //10mb
 const maxMetadataSize int = 20
 
 func ParseThermistorCSV(path string) (metadata [][2]string, points []models.ThermistorPoint, err error) {
-	info, err := os.Stat(path)
-
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if info.Size() > maxCSVSize {
-		return nil, nil, fmt.Errorf("file too large: %d mb -- should be under 10mb", info.Size()/(1024*1024))
-	}
-
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-
-	rows, err := reader.ReadAll()
-
+	rows, err := readRows(path)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	inTable := false
+	hasUncertainty := false
 	for i, row := range rows {
-		if len(row) != 2 || (row[0] == "" && row[1] == "") {
+		row = trimEmptyFields(row)
+		if len(row) < 2 || len(row) > 3 || (row[0] == "" && row[1] == "") {
 			continue
 		}
 
 		if strings.EqualFold(strings.TrimSpace(row[0]), "Temperature") && strings.EqualFold(strings.TrimSpace(row[1]), "Resistance") {
 			//Start of data
 			inTable = true
+			hasUncertainty = len(row) == 3
+			if hasUncertainty && !strings.EqualFold(strings.TrimSpace(row[2]), "Uncertainty") {
+				return nil, nil, fmt.Errorf("unknown column %q in row %d: the only optional column is Uncertainty (K)", strings.TrimSpace(row[2]), i+1)
+			}
 			continue
 		}
 
@@ -57,9 +45,23 @@ func ParseThermistorCSV(path string) (metadata [][2]string, points []models.Ther
 			if err1 != nil || err2 != nil {
 				return nil, nil, fmt.Errorf("failed to parse row %d: %v, %v, row content: %v", i+1, err1, err2, row)
 			}
-			points = append(points, models.ThermistorPoint{Temp: temp, Resistance: resistance})
+			point := models.ThermistorPoint{Temp: temp, Resistance: resistance}
+
+			if hasUncertainty {
+				if len(row) != 3 {
+					return nil, nil, fmt.Errorf("missing uncertainty in row %d, row content: %v", i+1, row)
+				}
+				uncertainty, err := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)
+				if err != nil || uncertainty <= 0 {
+					return nil, nil, fmt.Errorf("failed to parse uncertainty in row %d: must be a positive number of K, row content: %v", i+1, row)
+				}
+				point.Uncertainty = uncertainty
+			} else if len(row) == 3 {
+				return nil, nil, fmt.Errorf("unexpected third value in row %d without an Uncertainty column, row content: %v", i+1, row)
+			}
+			points = append(points, point)
 
-		} else {
+		} else if len(row) == 2 {
 			metadata = append(metadata, [2]string{strings.TrimSpace(row[0]), strings.TrimSpace(row[1])})
 		}
 
@@ -72,6 +74,85 @@ func ParseThermistorCSV(path string) (metadata [][2]string, points []models.Ther
 	return metadata, points, nil
 }
 
+// ParseINLCSV reads an ADC INL characterisation: rows of reported code and INL (LSB) below a
+// Code,INL header, returned sorted by code. The INL may not rise faster than the code.
+func ParseINLCSV(path string) ([]models.INLPoint, error) {
+	rows, err := readRows(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var table []models.INLPoint
+	inTable := false
+	for i, row := range rows {
+		row = trimEmptyFields(row)
+		if len(row) != 2 || (row[0] == "" && row[1] == "") {
+			continue
+		}
+
+		if strings.EqualFold(strings.TrimSpace(row[0]), "Code") && strings.EqualFold(strings.TrimSpace(row[1]), "INL") {
+			inTable = true
+			continue
+		}
+
+		if inTable {
+			code, err1 := strconv.ParseFloat(strings.TrimSpace(row[0]), 64)
+			inl, err2 := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("failed to parse row %d: %v, %v, row content: %v", i+1, err1, err2, row)
+			}
+			table = append(table, models.INLPoint{Code: code, INL: inl})
+		}
+	}
+
+	if !inTable || len(table) == 0 {
+		return nil, fmt.Errorf("failed to find Code/INL data in %s", path)
+	}
+
+	sort.Slice(table, func(a, b int) bool { return table[a].Code < table[b].Code })
+	for i := 1; i < len(table); i++ {
+		if table[i].Code == table[i-1].Code {
+			return nil, fmt.Errorf("duplicate INL entry for code %g in %s", table[i].Code, path)
+		}
+		if table[i].INL-table[i-1].INL >= table[i].Code-table[i-1].Code {
+			return nil, fmt.Errorf("INL rises by more than the codes between %g and %g in %s: the ADC would not be monotonic", table[i-1].Code, table[i].Code, path)
+		}
+	}
+	return table, nil
+}
+
+// readRows reads every row of the CSV at path, allowing rows of differing length, after
+// checking the file is under maxCSVSize.
+func readRows(path string) ([][]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Size() > maxCSVSize {
+		return nil, fmt.Errorf("file too large: %d mb -- should be under 10mb", info.Size()/(1024*1024))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // metadata rows and optional columns
+
+	return reader.ReadAll()
+}
+
+// trimEmptyFields drops trailing empty fields left by spreadsheet exports.
+func trimEmptyFields(row []string) []string {
+	for len(row) > 2 && strings.TrimSpace(row[len(row)-1]) == "" {
+		row = row[:len(row)-1]
+	}
+	return row
+}
+
 func ReadCSV(file string) ([]models.ThermistorPoint, [][2]string, []string, error) {
 	metadata, points, err := ParseThermistorCSV(file)
 	if err != nil {
@@ -91,9 +172,73 @@ func ReadCSV(file string) ([]models.ThermistorPoint, [][2]string, []string, erro
 		warnings = append(warnings, fmt.Sprintf("Large number of points (%d) - calculation may take a while.", len(points)))
 	}
 
+	if i := nonMonotonicPoint(points); i >= 0 {
+		warnings = append(warnings, fmt.Sprintf("Resistance changes direction at %.2f °C; the table should rise or fall monotonically with temperature.", points[i].Temp))
+	}
+
 	return points, metadata, warnings, nil
 }
 
+// DetectSensor returns the sensor type of a table: models.SensorNTC if resistance falls over
+// its temperature range and models.SensorPTC if it rises, or the NTC, PTC or RTD named by the
+// Type metadata row when it agrees with that slope.
+func DetectSensor(points []models.ThermistorPoint, metadata [][2]string) string {
+	coldest, hottest := points[0], points[0]
+	for _, p := range points {
+		if p.Temp < coldest.Temp {
+			coldest = p
+		}
+		if p.Temp > hottest.Temp {
+			hottest = p
+		}
+	}
+
+	slope := models.SensorNTC
+	if hottest.Resistance > coldest.Resistance {
+		slope = models.SensorPTC
+	}
+
+	for _, m := range metadata {
+		if !strings.EqualFold(strings.TrimSpace(m[0]), "Type") {
+			continue
+		}
+		switch sensor := strings.ToLower(strings.TrimSpace(m[1])); sensor {
+		case models.SensorNTC:
+			if slope == models.SensorNTC {
+				return sensor
+			}
+		case models.SensorPTC, models.SensorRTD:
+			if slope == models.SensorPTC {
+				return sensor
+			}
+		}
+	}
+	return slope
+}
+
+// nonMonotonicPoint returns the index in points of the first point, in temperature
+// order, where the slope of the table changes sign, or -1.
+func nonMonotonicPoint(points []models.ThermistorPoint) int {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return points[order[a]].Temp < points[order[b]].Temp })
+
+	var direction float64
+	for k := 1; k < len(order); k++ {
+		step := points[order[k]].Resistance - points[order[k-1]].Resistance
+		if step == 0 {
+			continue
+		}
+		if direction != 0 && (step > 0) != (direction > 0) {
+			return order[k]
+		}
+		direction = step
+	}
+	return -1
+}
+
 func WriteCSV(filePath string, header string, rows [][]string) error {
 	f, err := os.Create(filePath)
 	if err != nil {
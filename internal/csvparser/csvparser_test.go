@@ -3,6 +3,7 @@ package csvparser_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Eriosies/thermistor-lut-gen/internal/csvparser"
@@ -52,6 +53,32 @@ Temperature,Resistance
 	}
 }
 
+func TestParseThermistorCSV_Uncertainty(t *testing.T) {
+	csv := `Manufacturer,TestCorp,
+Temperature,Resistance,Uncertainty
+25,10000,0.1
+50,5000,0.2
+75,2500,0.5
+`
+	file := writeTempCSV(t, csv)
+
+	metadata, points, err := csvparser.ParseThermistorCSV(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(metadata) != 1 {
+		t.Errorf("expected 1 metadata row, got %d", len(metadata))
+	}
+
+	expected := []float64{0.1, 0.2, 0.5}
+	for i, pt := range points {
+		if pt.Uncertainty != expected[i] {
+			t.Errorf("point %d uncertainty = %g, want %g", i, pt.Uncertainty, expected[i])
+		}
+	}
+}
+
 func TestParseThermistorCSV_Errors(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -59,6 +86,10 @@ func TestParseThermistorCSV_Errors(t *testing.T) {
 	}{
 		{"missing table", "Metadata1,Value1"},
 		{"bad data", "Temperature,Resistance\n25,abc"},
+		{"missing uncertainty", "Temperature,Resistance,Uncertainty\n25,10000,0.1\n50,5000"},
+		{"bad uncertainty", "Temperature,Resistance,Uncertainty\n25,10000,-1"},
+		{"unknown column", "Temperature,Resistance,Weight\n25,10000,2\n50,5000,1"},
+		{"extra value", "Temperature,Resistance\n25,10000,0.1"},
 	}
 
 	for _, tt := range tests {
@@ -78,6 +109,52 @@ func TestParseThermistorCSV_Errors(t *testing.T) {
 	}
 }
 
+func TestParseINLCSV(t *testing.T) {
+	csv := `ADC,Example
+Code,INL
+4095,0.5
+0,-0.25
+2048,1.5
+`
+	file := writeTempCSV(t, csv)
+
+	table, err := csvparser.ParseINLCSV(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []models.INLPoint{{Code: 0, INL: -0.25}, {Code: 2048, INL: 1.5}, {Code: 4095, INL: 0.5}}
+	if len(table) != len(expected) {
+		t.Fatalf("expected %d INL points, got %d", len(expected), len(table))
+	}
+	for i, pt := range table {
+		if pt != expected[i] {
+			t.Errorf("point %d mismatch: got %+v, want %+v", i, pt, expected[i])
+		}
+	}
+}
+
+func TestParseINLCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing table", "Temperature,Resistance\n25,10000"},
+		{"bad data", "Code,INL\n100,abc"},
+		{"duplicate code", "Code,INL\n100,0.5\n100,0.75"},
+		{"non-monotonic", "Code,INL\n100,0\n102,3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeTempCSV(t, tt.content)
+			if _, err := csvparser.ParseINLCSV(file); err == nil {
+				t.Errorf("expected error for %s", tt.name)
+			}
+		})
+	}
+}
+
 func TestReadCSV_Warnings(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -105,3 +182,39 @@ func TestReadCSV_Warnings(t *testing.T) {
 		})
 	}
 }
+
+func TestReadCSV_NonMonotonic(t *testing.T) {
+	file := writeTempCSV(t, "Temperature,Resistance\n0,27000\n25,10000\n50,12000\n75,2500\n100,1250\n125,625\n")
+
+	_, _, warnings, err := csvparser.ReadCSV(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "50.00 °C") {
+		t.Errorf("expected a warning at 50 °C, got %v", warnings)
+	}
+}
+
+func TestDetectSensor(t *testing.T) {
+	ntc := []models.ThermistorPoint{{Temp: 50, Resistance: 4161}, {Temp: 0, Resistance: 27219}, {Temp: 25, Resistance: 10000}}
+	ptc := []models.ThermistorPoint{{Temp: 0, Resistance: 1630}, {Temp: 25, Resistance: 2000}, {Temp: 50, Resistance: 2417}}
+
+	tests := []struct {
+		name     string
+		points   []models.ThermistorPoint
+		metadata [][2]string
+		want     string
+	}{
+		{"ntc slope", ntc, nil, models.SensorNTC},
+		{"ptc slope", ptc, nil, models.SensorPTC},
+		{"rtd type", ptc, [][2]string{{"Name", "PT1000"}, {"Type", "RTD"}}, models.SensorRTD},
+		{"ptc type", ptc, [][2]string{{"Type", "PTC"}}, models.SensorPTC},
+		{"type against slope", ntc, [][2]string{{"Type", "RTD"}}, models.SensorNTC},
+	}
+
+	for _, tt := range tests {
+		if got := csvparser.DetectSensor(tt.points, tt.metadata); got != tt.want {
+			t.Errorf("%s: DetectSensor = %s; want %s", tt.name, got, tt.want)
+		}
+	}
+}
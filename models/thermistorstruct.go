@@ -1,14 +1,67 @@
 package models
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 const KelvinToCelsius float64 = 273.15
 const ResistanceMax float64 = 1e9
+const BetaReferenceTemp float64 = 25.0
+const PTCReferenceTemp float64 = 25.0
+
+const (
+	ModelSteinhart = "steinhart"
+	ModelBeta      = "beta"
+	ModelPoly      = "poly"
+)
+
+const (
+	SensorAuto = "auto"
+	SensorNTC  = "ntc"
+	SensorPTC  = "ptc"
+	SensorRTD  = "rtd"
+)
+
+// Circuit topologies: the thermistor to ground with the series resistor as pull-up, the
+// thermistor to the supply with the series resistor as pull-down, the low side divider as one
+// leg of a Wheatstone bridge read through an amplifier by a differential ADC, or the thermistor
+// driven by a constant current source.
+const (
+	TopologyLow     = "low"
+	TopologyHigh    = "high"
+	TopologyBridge  = "bridge"
+	TopologyCurrent = "current"
+)
+
+const (
+	FitLeastSquares = "lsq"
+	FitMinimax      = "minimax"
+	FitLM           = "lm"
+)
+
+const (
+	WeightNone  = "none"
+	WeightRange = "range"
+)
+
+// INLPoint is one entry of an ADC characterisation: the integral non-linearity at a reported
+// code, as the firmware reads it.
+type INLPoint struct {
+	Code float64
+	INL  float64 // LSB, reported code minus ideal
+}
 
 type ThermistorPoint struct {
-	Temp       float64
-	Resistance float64
+	Temp        float64
+	Resistance  float64
+	Uncertainty float64 // K, 0 = not given
+	Weight      float64 // relative least squares weight, 0 = unweighted
 }
 
 type Config struct {
+	Command        string
 	InputFile      string
 	OutputDir      string
 	BaseName       string
@@ -21,6 +74,59 @@ type Config struct {
 	LowerLimitTemp float64
 	NameFlag       string
 	FixedPoint     uint
+	Model          string
+	Order          uint
+	Weighting      string
+	Fit            string
+	FitInRange     bool
+	Segments       uint
+	Bands          []float64
+	Exact          bool
+	Robust         string
+	ExcludeOutlier bool
+	InverseTemps   []float64
+	InverseStep    float64
+	InverseVrefs   []float64 // V, measured ADC references to tabulate codes at
+	Sensor         string
+	CVDCoeff       []float64 // {A, B, C}, nil = fit from the CSV
+	R0             float64   // Ω, 0 = fit from the CSV
+	PTCOrder       uint
+	Dissipation    float64 // mW/K, 0 = not given
+	SelfHeating    bool
+	TolR25         float64 // %
+	TolB           float64 // %
+	TolRS          float64 // %
+	TolRP          float64 // %
+	VrefTol        float64 // % mismatch between the divider supply and the ADC reference
+	ADCNoise       float64 // LSB rms
+	ADCINL         float64 // LSB peak
+	Trials         int
+	Seed           int64
+	OptMetric      string
+	ESeries        string
+	OptimiseRP     bool
+	Top            int
+	LinearTol      float64 // K, 0 = report only
+	RSGiven        bool    // -rs was passed explicitly
+	LUTAuto        bool    // pick the smallest LUTSize within MaxLUTError
+	MaxLUTError    float64 // K
+	Topology       string
+	BridgeTop      float64 // kΩ, reference leg resistor to Vref
+	BridgeBottom   float64 // kΩ, reference leg resistor to ground
+	AmpGain        float64 // V/V
+	AmpOffset      float64 // V at the ADC input
+	Excitation     float64 // µA, current source
+	RRef           float64 // kΩ, ratiometric reference resistor in the current path, 0 = none
+	VoltageSupply  float64 // V, divider or bridge supply, 0 = VoltageRef
+	RuntimeVref    bool    // generate C taking the measured ADC reference
+	VrefintCal     float64 // V, ADC reference the VREFINT calibration value was taken at
+	RSource        float64 // kΩ, protection or filter resistor between the circuit and the ADC pin
+	RInput         float64 // kΩ, ADC input resistance, 0 = none
+	Leakage        float64 // nA, ADC pin leakage current, positive into the pin
+	ADCOffset      float64 // LSB, reported code minus ideal at zero
+	ADCGain        float64 // ppm, gain error
+	INLFile        string
+	INL            []INLPoint
 }
 
 type DeviationTable struct {
@@ -30,6 +136,94 @@ type DeviationTable struct {
 	Deviation       float64
 }
 
+// FitStats describes the quality of a fit. Residual based figures are in K; standard
+// errors are for the coefficients in 1/T space.
+type FitStats struct {
+	Points          int               `json:"points"`
+	DOF             int               `json:"degreesOfFreedom"`
+	RMS             float64           `json:"rmsK"`
+	RSquared        float64           `json:"rSquared"`
+	Condition       float64           `json:"conditionNumber"`
+	ConfidenceLevel float64           `json:"confidenceLevel"`
+	Powers          []int             `json:"powers"`
+	Coeff           []float64         `json:"coefficients"`
+	StdErr          []float64         `json:"standardErrors,omitempty"`
+	Band            []ConfidencePoint `json:"confidenceBand,omitempty"`
+}
+
+// ConfidencePoint is the fitted temperature (°C) at a resistance (Ω) with the half width (K)
+// of its confidence interval.
+type ConfidencePoint struct {
+	Resistance  float64 `json:"resistance"`
+	Temperature float64 `json:"temperature"`
+	HalfWidth   float64 `json:"halfWidthK"`
+}
+
+// Segment is one temperature band of a piecewise ln(R) polynomial fit. It applies to
+// resistances at or above Threshold, the resistance at its Upper edge (0 for the last band).
+type Segment struct {
+	Lower     float64
+	Upper     float64
+	Threshold float64
+	Coeff     []float64
+}
+
+// DetermineDissipation returns the dissipation constant (mW/K) from cfg, or else from the
+// Dissipation_Constant metadata row, or 0 if neither gives one.
+func DetermineDissipation(cfg Config, metadata [][2]string) (float64, error) {
+	if cfg.Dissipation != 0 {
+		return cfg.Dissipation, nil
+	}
+
+	for _, m := range metadata {
+		key, val := m[0], strings.Fields(m[1])
+		if len(val) != 0 && (strings.EqualFold(key, "Dissipation_Constant") || strings.EqualFold(key, "Dissipation Constant")) {
+			dc, err := strconv.ParseFloat(val[0], 64)
+			if err != nil || dc <= 0 {
+				return 0, fmt.Errorf("invalid dissipation constant %q in CSV metadata: must be a positive number of mW/K", m[1])
+			}
+			return dc, nil
+		}
+	}
+	return 0, nil
+}
+
+// SignedADCValue returns an internal ADC code as the firmware reads it: two's complement for
+// the bridge's differential ADC, whose codes are kept offset binary, otherwise unchanged.
+func SignedADCValue(cfg Config, adcValue uint) int {
+	if cfg.Topology == TopologyBridge {
+		return int(adcValue) - 1<<(cfg.ADCResolution-1)
+	}
+	return int(adcValue)
+}
+
+// ADCReference returns the full scale voltage (V) of the ADC: the drop of the excitation
+// current across the reference resistor for a ratiometric current source, otherwise Vref.
+func ADCReference(cfg Config) float64 {
+	if cfg.Topology == TopologyCurrent && cfg.RRef != 0 {
+		return cfg.Excitation * 1e-6 * cfg.RRef * 1000
+	}
+	return cfg.VoltageRef
+}
+
+// SupplyVoltage returns the voltage (V) the divider or bridge is excited with: VoltageSupply if
+// set, otherwise the ADC reference, which makes the reading ratiometric.
+func SupplyVoltage(cfg Config) float64 {
+	if cfg.VoltageSupply != 0 {
+		return cfg.VoltageSupply
+	}
+	return cfg.VoltageRef
+}
+
+// Ratiometric reports whether the divider or bridge supply is the ADC reference, so that its
+// tolerance cancels out of the reading. A current source is ratiometric with a reference resistor.
+func Ratiometric(cfg Config) bool {
+	if cfg.Topology == TopologyCurrent {
+		return cfg.RRef != 0
+	}
+	return SupplyVoltage(cfg) == cfg.VoltageRef
+}
+
 func DetermineBaseName(cfg Config, metadata [][2]string) string {
 	if cfg.NameFlag != "" {
 		return cfg.NameFlag
@@ -0,0 +1,85 @@
+package thermistor
+
+import (
+	"sort"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// adcErrors reports whether cfg has an ADC offset, gain error or INL table.
+func adcErrors(cfg models.Config) bool {
+	return cfg.ADCOffset != 0 || cfg.ADCGain != 0 || len(cfg.INL) != 0
+}
+
+// adcTransfer returns the code the ADC of cfg reports for an ideal, unrounded code: scaled by the
+// gain error, shifted by the offset and bent by the INL table at the reported code. Bridge codes
+// are corrected as the signed codes the differential ADC reports.
+func adcTransfer(cfg models.Config, code float64) float64 {
+	if !adcErrors(cfg) {
+		return code
+	}
+
+	var zero float64
+	if cfg.Topology == models.TopologyBridge {
+		zero = float64(uint(1) << (cfg.ADCResolution - 1))
+	}
+
+	c := (code-zero)*(1+cfg.ADCGain*1e-6) + cfg.ADCOffset
+	return c + reportedINL(cfg.INL, c) + zero
+}
+
+// inlAt returns the INL (LSB) of table at code, interpolated linearly between entries and held
+// at the end values beyond them.
+func inlAt(table []models.INLPoint, code float64) float64 {
+	if len(table) == 0 {
+		return 0
+	}
+
+	i := sort.Search(len(table), func(i int) bool { return table[i].Code >= code })
+	if i == 0 {
+		return table[0].INL
+	}
+	if i == len(table) {
+		return table[len(table)-1].INL
+	}
+
+	a, b := table[i-1], table[i]
+	return a.INL + (b.INL-a.INL)*(code-a.Code)/(b.Code-a.Code)
+}
+
+// reportedINL returns the INL (LSB) of table at the code the ADC reports for code before INL,
+// the reported code r solving r = code + inlAt(table, r). The table is piecewise linear, so r
+// is found exactly on the entry interval where r - INL passes code.
+func reportedINL(table []models.INLPoint, code float64) float64 {
+	if len(table) == 0 {
+		return 0
+	}
+
+	for i := 1; i < len(table); i++ {
+		a, b := table[i-1], table[i]
+		if code > b.Code-b.INL {
+			continue
+		}
+		if code <= a.Code-a.INL {
+			return a.INL
+		}
+
+		// r - INL rises with r as long as the INL rises by less than the codes between entries.
+		r := a.Code + (code-(a.Code-a.INL))*(b.Code-a.Code)/((b.Code-b.INL)-(a.Code-a.INL))
+		return inlAt(table, r)
+	}
+	return table[len(table)-1].INL
+}
+
+// ADCErrorCorrection scans every ADC code whose temperature lies within the limits of cfg and
+// returns the largest correction (K) the ADC offset, gain error and INL make to the temperature
+// read through model, and the temperature (°C) it occurs at. It is 0 without ADC errors.
+func ADCErrorCorrection(cfg models.Config, model Model) (float64, float64) {
+	if !adcErrors(cfg) {
+		return 0, 0
+	}
+
+	ideal := cfg
+	ideal.ADCOffset, ideal.ADCGain, ideal.INL = 0, 0, nil
+	return largestCorrection(cfg, ideal, model)
+}
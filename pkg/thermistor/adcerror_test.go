@@ -0,0 +1,114 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestADCTransfer(t *testing.T) {
+	inl := []models.INLPoint{{Code: 0, INL: 0}, {Code: 2000, INL: 2}, {Code: 4000, INL: 0}}
+	cfg := models.Config{ADCResolution: 12, ADCOffset: 3, ADCGain: 1000, INL: inl}
+
+	// 1000 reads 1004 through the gain error and 3 LSB offset, plus the INL of 1/1000 of the
+	// reported code r: r = 1004 + r/1000.
+	if got := adcTransfer(cfg, 1000); !floatAlmostEqual(got, 1004/0.999, 1e-9) {
+		t.Errorf("adcTransfer(1000) = %g; want %g", got, 1004/0.999)
+	}
+	if got := inlAt(inl, 5000); got != 0 {
+		t.Errorf("INL beyond the table = %g; want the end value 0", got)
+	}
+
+	// Bridge codes are corrected about mid-scale, where the signed code is 0.
+	cfg.Topology = models.TopologyBridge
+	cfg.INL = nil
+	if got := adcTransfer(cfg, 2048); !floatAlmostEqual(got, 2051, 1e-9) {
+		t.Errorf("bridge adcTransfer(2048) = %g; want 2051", got)
+	}
+
+	if got := adcTransfer(models.Config{ADCResolution: 12}, 1000); got != 1000 {
+		t.Errorf("ideal adcTransfer(1000) = %g; want 1000", got)
+	}
+}
+
+func TestADCTransfer_ReportedCode(t *testing.T) {
+	// The INL steps up by 5 LSB over codes 100 to 110, where it differs most between the ideal
+	// and reported code.
+	inl := []models.INLPoint{{Code: 0, INL: 0}, {Code: 100, INL: 0}, {Code: 110, INL: 5}, {Code: 200, INL: 5}}
+	cfg := models.Config{ADCResolution: 12, INL: inl}
+
+	// Ideal 104 is reported as 108, where the table gives 4 LSB; at 104 itself it gives 2.
+	got := adcTransfer(cfg, 104)
+	if !floatAlmostEqual(got, 108, 1e-9) {
+		t.Errorf("adcTransfer(104) = %g; want 108", got)
+	}
+	if inl := inlAt(cfg.INL, got); !floatAlmostEqual(got-104, inl, 1e-9) {
+		t.Errorf("reported code %g is %g LSB from ideal; want the %g LSB INL at it", got, got-104, inl)
+	}
+
+	for _, tt := range []struct{ code, want float64 }{{50, 50}, {96, 96}, {106, 111}, {150, 155}, {300, 305}} {
+		if got := adcTransfer(cfg, tt.code); !floatAlmostEqual(got, tt.want, 1e-9) {
+			t.Errorf("adcTransfer(%g) = %g; want %g", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestResistanceFromADCValue_ADCErrors(t *testing.T) {
+	inl := []models.INLPoint{{Code: 0, INL: 0}, {Code: 32768, INL: 4}, {Code: 65535, INL: 0}}
+	for _, topology := range []string{models.TopologyLow, models.TopologyHigh, models.TopologyCurrent, models.TopologyBridge} {
+		cfg := models.Config{
+			ADCResolution: 16,
+			VoltageRef:    3.3,
+			RS:            10,
+			Topology:      topology,
+			Excitation:    100,
+			BridgeTop:     10,
+			BridgeBottom:  10,
+			AmpGain:       1,
+			ADCOffset:     -20,
+			ADCGain:       2000,
+			INL:           inl,
+		}
+
+		for _, r := range []float64{2000, 10000, 25000} {
+			adc := ADCValueFromResistance(cfg, r)
+			if back := resistanceFromADCValue(cfg, adc); !floatAlmostEqualPercentage(back, r, 0.002) {
+				t.Errorf("%s: resistance %g -> ADC %d -> %g", topology, r, adc, back)
+			}
+		}
+	}
+}
+
+func TestADCErrorCorrection(t *testing.T) {
+	model := SteinhartModel(testSteinhartCoeff)
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+		LUTSize:        64,
+	}
+
+	if worst, _ := ADCErrorCorrection(cfg, model); worst != 0 {
+		t.Errorf("ideal ADC correction = %g; want 0", worst)
+	}
+
+	ideal, _, _, err := GenerateLUT(cfg, testSteinhartCoeff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A positive offset reads every code high, a lower resistance on the low side divider.
+	cfg.ADCOffset = 10
+	if worst, _ := ADCErrorCorrection(cfg, model); worst <= 0 {
+		t.Errorf("offset correction = %g; want positive", worst)
+	}
+	shifted, _, _, err := GenerateLUT(cfg, testSteinhartCoeff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shifted[32] <= ideal[32] {
+		t.Errorf("LUT with a positive offset = %g °C; want above the ideal %g °C", shifted[32], ideal[32])
+	}
+}
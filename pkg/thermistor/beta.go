@@ -0,0 +1,64 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// BetaCalculation returns the temperature (K) for beta = {R25, B}, R25 being the
+// resistance at models.BetaReferenceTemp.
+func BetaCalculation(resistance float64, beta [2]float64) float64 {
+	t0 := models.BetaReferenceTemp + KelvinToCelsius
+	return 1 / (1/t0 + math.Log(resistance/beta[0])/beta[1])
+}
+
+func BetaModel(beta [2]float64) Model {
+	return func(resistance float64) float64 {
+		return BetaCalculation(resistance, beta)
+	}
+}
+
+// Powers of ln(R) used by the Beta model.
+var BetaPowers = []int{0, 1}
+
+// FindBetaCoefficients fits 1/T = a + b*ln(R) and returns {R25, B}.
+func FindBetaCoefficients(points []models.ThermistorPoint) ([2]float64, error) {
+	var result [2]float64
+
+	coeffs, err := FindLnPolynomialCoefficients(points, BetaPowers)
+	if err != nil {
+		return result, err
+	}
+
+	return BetaFromPolynomial(coeffs)
+}
+
+// BetaFromPolynomial converts dense {a, b} of 1/T = a + b*ln(R) into {R25, B}.
+func BetaFromPolynomial(coeffs []float64) ([2]float64, error) {
+	var result [2]float64
+
+	if len(coeffs) != 2 {
+		return result, fmt.Errorf("beta model needs 2 coefficients, got %d", len(coeffs))
+	}
+	if coeffs[1] <= 0 {
+		return result, fmt.Errorf("beta fit produced a non-positive B constant (1/B = %.3g); data is not NTC", coeffs[1])
+	}
+
+	t0 := models.BetaReferenceTemp + KelvinToCelsius
+	result[1] = 1 / coeffs[1]
+	result[0] = math.Exp((1/t0 - coeffs[0]) * result[1])
+
+	return result, nil
+}
+
+// BetaToPolynomial converts {R25, B} into dense {a, b} of 1/T = a + b*ln(R).
+func BetaToPolynomial(beta [2]float64) []float64 {
+	t0 := models.BetaReferenceTemp + KelvinToCelsius
+	return []float64{1/t0 - math.Log(beta[0])/beta[1], 1 / beta[1]}
+}
+
+func CheckBetaDeviation(points []models.ThermistorPoint, beta [2]float64) ([]models.DeviationTable, float64, float64) {
+	return CheckModelDeviation(points, BetaModel(beta))
+}
@@ -0,0 +1,65 @@
+package thermistor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+var testBeta = [2]float64{10000, 3950}
+
+func betaTestPoints() []models.ThermistorPoint {
+	var points []models.ThermistorPoint
+	t0 := models.BetaReferenceTemp + KelvinToCelsius
+
+	for temp := -40.0; temp <= 125; temp += 5 {
+		r := testBeta[0] * math.Exp(testBeta[1]*(1/(temp+KelvinToCelsius)-1/t0))
+		points = append(points, models.ThermistorPoint{Temp: temp, Resistance: r})
+	}
+	return points
+}
+
+func TestBetaCalculation(t *testing.T) {
+	result := BetaCalculation(testBeta[0], testBeta) - KelvinToCelsius
+	if !floatAlmostEqual(result, models.BetaReferenceTemp, 1e-9) {
+		t.Errorf("result = %f; want %f", result, models.BetaReferenceTemp)
+	}
+
+	for _, p := range betaTestPoints() {
+		result := BetaCalculation(p.Resistance, testBeta) - KelvinToCelsius
+		if !floatAlmostEqual(result, p.Temp, 1e-6) {
+			t.Errorf("result = %f; want %f", result, p.Temp)
+		}
+	}
+}
+
+func TestFindBetaCoefficients(t *testing.T) {
+	result, err := FindBetaCoefficients(betaTestPoints())
+	if err != nil {
+		t.Fatalf("FindBetaCoefficients returned error: %v", err)
+	}
+
+	for i := range testBeta {
+		if !floatAlmostEqualPercentage(result[i], testBeta[i], 1e-6) {
+			t.Errorf("result[%d] = %f; want %f", i, result[i], testBeta[i])
+		}
+	}
+
+	_, maxDev, _ := CheckBetaDeviation(betaTestPoints(), result)
+	if maxDev > 1e-6 {
+		t.Errorf("max deviation = %.3g, want 0", maxDev)
+	}
+}
+
+func TestFindBetaCoefficients_PTC(t *testing.T) {
+	points := []models.ThermistorPoint{
+		{Temp: 0, Resistance: 1000},
+		{Temp: 50, Resistance: 1200},
+		{Temp: 100, Resistance: 1400},
+	}
+
+	if _, err := FindBetaCoefficients(points); err == nil {
+		t.Errorf("expected error for rising resistance, got nil")
+	}
+}
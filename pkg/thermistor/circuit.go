@@ -0,0 +1,213 @@
+package thermistor
+
+import (
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// Bisection steps of solveResistanceFromADCValue, enough for float64 precision, and the lowest
+// resistance (Ω) it searches, up to models.ResistanceMax.
+const (
+	solveIterations    int     = 100
+	solveMinResistance float64 = 0.1
+)
+
+// resistanceFromADCValue returns the thermistor resistance (Ω) read at adcValue by the circuit
+// in cfg. Signed bridge readings are offset binary, so code 0 is the most negative reading.
+func resistanceFromADCValue(cfg models.Config, adcValue uint) float64 {
+	// The input load and ADC errors have no closed form inverse.
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+	if (inputLoaded(cfg) || adcErrors(cfg)) && adcValue != 0 && adcValue != adcMax {
+		return solveResistanceFromADCValue(cfg, adcValue)
+	}
+
+	switch cfg.Topology {
+	case models.TopologyHigh:
+		return getHighSideResistanceFromADCValue(cfg.VoltageRef, models.SupplyVoltage(cfg), adcValue, cfg.ADCResolution, cfg.RS*1000, cfg.RP*1000)
+	case models.TopologyBridge:
+		return getBridgeResistanceFromADCValue(cfg, adcValue)
+	case models.TopologyCurrent:
+		return getCurrentResistanceFromADCValue(cfg, adcValue)
+	default:
+		return getResistanceFromADCValue(cfg.VoltageRef, models.SupplyVoltage(cfg), adcValue, cfg.ADCResolution, cfg.RS*1000, cfg.RP*1000)
+	}
+}
+
+// adcCode returns the unrounded ADC code the circuit and ADC in cfg report for a thermistor
+// resistance (Ω).
+func adcCode(cfg models.Config, resistance float64) float64 {
+	return adcTransfer(cfg, codeFromRatio(cfg, circuitRatio(cfg, resistance)))
+}
+
+// solveResistanceFromADCValue is resistanceFromADCValue for any circuit, found by bisecting ln(R)
+// for the resistance whose code is adcValue. Codes the circuit cannot reach read as the nearest
+// end of the resistance range.
+func solveResistanceFromADCValue(cfg models.Config, adcValue uint) float64 {
+	lo, hi := math.Log(solveMinResistance), math.Log(models.ResistanceMax)
+	codeLo, codeHi := adcCode(cfg, solveMinResistance), adcCode(cfg, models.ResistanceMax)
+	target := float64(adcValue)
+
+	// Bisect on the code rising with ln(R).
+	sign := 1.0
+	if codeHi < codeLo {
+		sign = -1
+	}
+	if sign*(target-codeLo) <= 0 {
+		return 0.0
+	}
+	if sign*(target-codeHi) >= 0 {
+		return models.ResistanceMax
+	}
+
+	for i := 0; i < solveIterations; i++ {
+		mid := (lo + hi) / 2
+		if sign*(adcCode(cfg, math.Exp(mid))-target) < 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return math.Exp((lo + hi) / 2)
+}
+
+// circuitRatio returns the ADC input as a fraction of the ADC reference for a thermistor
+// resistance (Ω), signed for the bridge.
+func circuitRatio(cfg models.Config, resistance float64) float64 {
+	switch cfg.Topology {
+	case models.TopologyBridge:
+		return bridgeRatio(cfg, resistance)
+	case models.TopologyCurrent:
+		return loadedRatio(cfg, resistance, currentRatio(cfg, resistance))
+	}
+	return loadedRatio(cfg, resistance, supplyRatio(cfg)*dividerRatio(cfg.Topology, resistance, cfg.RS*1000, cfg.RP*1000))
+}
+
+// supplyRatio returns the divider or bridge supply as a fraction of the ADC reference.
+func supplyRatio(cfg models.Config) float64 {
+	if models.Ratiometric(cfg) {
+		return 1
+	}
+	return models.SupplyVoltage(cfg) / cfg.VoltageRef
+}
+
+// codeFromRatio returns the unrounded ADC code for an input of ratio times the reference.
+func codeFromRatio(cfg models.Config, ratio float64) float64 {
+	codes := float64(uint(1) << cfg.ADCResolution)
+	if cfg.Topology == models.TopologyBridge {
+		return codes/2 + ratio*codes/2
+	}
+	return codes * ratio
+}
+
+// dividerRatio returns Vout/Vref of a divider of series resistor rs and parallel resistor rp (Ω),
+// 0 = none, for a thermistor resistance (Ω).
+func dividerRatio(topology string, resistance, rs, rp float64) float64 {
+	rNet := resistance
+	if rp != 0 {
+		rNet = resistance * rp / (resistance + rp)
+	}
+
+	if topology == models.TopologyHigh {
+		return rs / (rs + rNet)
+	}
+	return rNet / (rs + rNet)
+}
+
+// dividerResistance returns the thermistor resistance (Ω) at a divider ratio Vout/Vref, the
+// inverse of dividerRatio, or models.ResistanceMax beyond the parallel resistor alone.
+func dividerResistance(topology string, ratio, rs, rp float64) float64 {
+	r := rs * ratio / (1 - ratio)
+	if topology == models.TopologyHigh {
+		r = rs * (1 - ratio) / ratio
+	}
+
+	if rp != 0 {
+		if r >= rp {
+			return models.ResistanceMax
+		}
+		r = 1 / (1/r - 1/rp)
+	}
+	return r
+}
+
+// bridgeRatio returns the amplified bridge output as a fraction of the reference: the low side
+// divider of the thermistor leg, as loaded by the amplifier input, minus the reference leg, times
+// the amplifier gain, plus its offset.
+func bridgeRatio(cfg models.Config, resistance float64) float64 {
+	thermistorLeg := dividerRatio(models.TopologyLow, resistance, cfg.RS*1000, cfg.RP*1000)
+	if inputLoaded(cfg) {
+		thermistorLeg = loadedRatio(cfg, resistance, supplyRatio(cfg)*thermistorLeg) / supplyRatio(cfg)
+	}
+	referenceLeg := cfg.BridgeBottom / (cfg.BridgeTop + cfg.BridgeBottom)
+	return cfg.AmpGain*supplyRatio(cfg)*(thermistorLeg-referenceLeg) + cfg.AmpOffset/cfg.VoltageRef
+}
+
+// getBridgeResistanceFromADCValue is getResistanceFromADCValue for the bridge, with adcValue
+// the offset binary differential reading.
+func getBridgeResistanceFromADCValue(cfg models.Config, adcValue uint) float64 {
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+	half := float64(uint(1) << (cfg.ADCResolution - 1))
+
+	if adcValue == 0 {
+		return 0.0
+	}
+	if adcValue == adcMax {
+		return models.ResistanceMax
+	}
+
+	ratio := (float64(adcValue) - half) / half
+	referenceLeg := cfg.BridgeBottom / (cfg.BridgeTop + cfg.BridgeBottom)
+	thermistorLeg := (ratio-cfg.AmpOffset/cfg.VoltageRef)/(cfg.AmpGain*supplyRatio(cfg)) + referenceLeg
+
+	// Outside the supply the reading is clipped by the amplifier or ADC.
+	if thermistorLeg <= 0 {
+		return 0.0
+	}
+	if thermistorLeg >= 1 {
+		return models.ResistanceMax
+	}
+	return dividerResistance(models.TopologyLow, thermistorLeg, cfg.RS*1000, cfg.RP*1000)
+}
+
+// currentRatio returns the voltage the excitation current develops across the thermistor, and
+// the parallel resistor if fitted, as a fraction of the ADC reference.
+func currentRatio(cfg models.Config, resistance float64) float64 {
+	rNet := resistance
+	if rp := cfg.RP * 1000; rp != 0 {
+		rNet = resistance * rp / (resistance + rp)
+	}
+	return cfg.Excitation * 1e-6 * rNet / models.ADCReference(cfg)
+}
+
+// getCurrentResistanceFromADCValue is getResistanceFromADCValue for the current source, where
+// the resistance is the voltage read over the excitation current. With a reference resistor
+// the excitation current cancels and the resistance is a fraction of it.
+func getCurrentResistanceFromADCValue(cfg models.Config, adcValue uint) float64 {
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+
+	if adcValue == 0 {
+		return 0.0
+	}
+	if adcValue == adcMax {
+		return models.ResistanceMax
+	}
+
+	v := models.ADCReference(cfg) * float64(adcValue) / float64(adcMax+1)
+	r := v / (cfg.Excitation * 1e-6)
+
+	if rp := cfg.RP * 1000; rp != 0 {
+		// The parallel resistor caps the network, so a higher reading is out of range.
+		if r >= rp {
+			return models.ResistanceMax
+		}
+		r = 1 / (1/r - 1/rp)
+	}
+	return r
+}
+
+// lowCodeIsHot reports whether ADC code 0 is the hot end of the LUT: the lowest resistance
+// for an NTC on the low side or in the bridge, or the highest for a PTC on the high side.
+func lowCodeIsHot(cfg models.Config) bool {
+	return IsPTC(cfg) == (cfg.Topology == models.TopologyHigh)
+}
@@ -0,0 +1,169 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// IEC 60751 Callendar-Van Dusen coefficients {A, B, C} for industrial platinum RTDs.
+var IECCVD = [3]float64{3.9083e-3, -5.775e-7, -4.183e-12}
+
+const cvdIterations int = 20
+
+// Temperature scale (K) of the columns of fits in resistance, keeps the design matrix
+// well conditioned.
+const tempColumnScale float64 = 100
+
+// CVDResistance returns the RTD resistance (Ω) at tempC for cvd = {R0, A, B, C}.
+// The C term only applies below 0 °C.
+func CVDResistance(tempC float64, cvd [4]float64) float64 {
+	ratio := 1 + cvd[1]*tempC + cvd[2]*tempC*tempC
+	if tempC < 0 {
+		ratio += cvd[3] * (tempC - 100) * tempC * tempC * tempC
+	}
+	return cvd[0] * ratio
+}
+
+// CVDCalculation returns the temperature (K) for cvd = {R0, A, B, C}. At and above R0 the
+// quadratic is solved directly, below it the quadratic root is refined with Newton's method.
+func CVDCalculation(resistance float64, cvd [4]float64) float64 {
+	r0, a, b, c := cvd[0], cvd[1], cvd[2], cvd[3]
+	ratio := resistance / r0
+
+	var t float64
+	if b == 0 {
+		t = (ratio - 1) / a
+	} else {
+		disc := a*a - 4*b*(1-ratio)
+		if disc < 0 {
+			// Beyond the vertex of the quadratic, far outside any real sensor range.
+			disc = 0
+		}
+		t = (-a + math.Sqrt(disc)) / (2 * b)
+	}
+
+	if ratio < 1 && c != 0 {
+		for i := 0; i < cvdIterations; i++ {
+			f := 1 + a*t + b*t*t + c*(t-100)*t*t*t - ratio
+			df := a + 2*b*t + c*(4*t*t*t-300*t*t)
+			step := f / df
+			t -= step
+			if math.Abs(step) < 1e-9 {
+				break
+			}
+		}
+	}
+
+	return t + KelvinToCelsius
+}
+
+func CVDModel(cvd [4]float64) Model {
+	return func(resistance float64) float64 {
+		return CVDCalculation(resistance, cvd)
+	}
+}
+
+// FindCVDCoefficients fits {R0, A, B, C} to the table by least squares in resistance.
+// C is only fitted when the table has points below 0 °C, otherwise it is 0.
+func FindCVDCoefficients(points []models.ThermistorPoint) ([4]float64, error) {
+	var result [4]float64
+
+	cold := false
+	for _, p := range points {
+		if p.Temp < 0 {
+			cold = true
+		}
+	}
+
+	cols := 3
+	if cold {
+		cols = 4
+	}
+	if len(points) < cols {
+		return result, fmt.Errorf("not enough points (%d) to fit %d Callendar-Van Dusen coefficients", len(points), cols)
+	}
+
+	X := make([][]float64, len(points))
+	Y := make([]float64, len(points))
+
+	for i, p := range points {
+		x := p.Temp / tempColumnScale
+		X[i] = []float64{1, x, x * x}
+		if cold {
+			var term float64
+			if p.Temp < 0 {
+				term = (x - 1) * x * x * x
+			}
+			X[i] = append(X[i], term)
+		}
+		Y[i] = p.Resistance
+	}
+
+	coeffs, err := weightedLeastSquares(X, Y, resistanceWeights(points))
+	if err != nil {
+		return result, err
+	}
+
+	result[0] = coeffs[0]
+	if result[0] <= 0 {
+		return result, fmt.Errorf("Callendar-Van Dusen fit produced a non-positive R0 (%.3g Ω)", result[0])
+	}
+	result[1] = coeffs[1] / (tempColumnScale * result[0])
+	result[2] = coeffs[2] / (tempColumnScale * tempColumnScale * result[0])
+	if cold {
+		result[3] = coeffs[3] / (tempColumnScale * tempColumnScale * tempColumnScale * tempColumnScale * result[0])
+	}
+
+	if result[1] <= 0 {
+		return result, fmt.Errorf("Callendar-Van Dusen fit produced a non-positive A (%.3g); data is not an RTD", result[1])
+	}
+
+	return result, nil
+}
+
+// FindCVDR0 fits only R0 for known {A, B, C}, e.g. IECCVD, and returns {R0, A, B, C}.
+func FindCVDR0(points []models.ThermistorPoint, abc [3]float64) ([4]float64, error) {
+	result := [4]float64{0, abc[0], abc[1], abc[2]}
+
+	X := make([][]float64, len(points))
+	Y := make([]float64, len(points))
+	for i, p := range points {
+		X[i] = []float64{CVDResistance(p.Temp, [4]float64{1, abc[0], abc[1], abc[2]})}
+		Y[i] = p.Resistance
+	}
+
+	coeffs, err := weightedLeastSquares(X, Y, resistanceWeights(points))
+	if err != nil {
+		return result, err
+	}
+	if coeffs[0] <= 0 {
+		return result, fmt.Errorf("Callendar-Van Dusen fit produced a non-positive R0 (%.3g Ω)", coeffs[0])
+	}
+
+	result[0] = coeffs[0]
+	return result, nil
+}
+
+// resistanceWeights returns the point weights for a fit in resistance (RTD and PTC).
+// Uncertainty weights from WeightPoints are in 1/T space, so their T⁴ factor is removed;
+// dR/dT of these sensors is near constant.
+func resistanceWeights(points []models.ThermistorPoint) []float64 {
+	weights := pointWeights(points)
+	if weights == nil {
+		return nil
+	}
+
+	for i, p := range points {
+		if p.Uncertainty > 0 {
+			tK := p.Temp + KelvinToCelsius
+			weights[i] /= tK * tK * tK * tK
+		}
+	}
+	return weights
+}
+
+func CheckCVDDeviation(points []models.ThermistorPoint, cvd [4]float64) ([]models.DeviationTable, float64, float64) {
+	return CheckModelDeviation(points, CVDModel(cvd))
+}
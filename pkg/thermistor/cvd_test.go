@@ -0,0 +1,132 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+var testCVD = [4]float64{1000, IECCVD[0], IECCVD[1], IECCVD[2]}
+
+func cvdTestPoints() []models.ThermistorPoint {
+	var points []models.ThermistorPoint
+	for temp := -200.0; temp <= 400; temp += 20 {
+		points = append(points, models.ThermistorPoint{Temp: temp, Resistance: CVDResistance(temp, testCVD)})
+	}
+	return points
+}
+
+func TestCVDResistance(t *testing.T) {
+	// IEC 60751 table values for PT1000
+	tests := []struct {
+		temp, want float64
+	}{
+		{-100, 602.56},
+		{0, 1000},
+		{100, 1385.06},
+		{200, 1758.56},
+	}
+
+	for _, tt := range tests {
+		result := CVDResistance(tt.temp, testCVD)
+		if !floatAlmostEqual(result, tt.want, 0.01) {
+			t.Errorf("CVDResistance(%.0f) = %f; want %f", tt.temp, result, tt.want)
+		}
+	}
+}
+
+func TestCVDCalculation(t *testing.T) {
+	for _, p := range cvdTestPoints() {
+		result := CVDCalculation(p.Resistance, testCVD) - KelvinToCelsius
+		if !floatAlmostEqual(result, p.Temp, 1e-6) {
+			t.Errorf("CVDCalculation(%f) = %f; want %f", p.Resistance, result, p.Temp)
+		}
+	}
+}
+
+func TestFindCVDCoefficients(t *testing.T) {
+	result, err := FindCVDCoefficients(cvdTestPoints())
+	if err != nil {
+		t.Fatalf("FindCVDCoefficients returned error: %v", err)
+	}
+
+	for i := range testCVD {
+		if !floatAlmostEqualPercentage(result[i], testCVD[i], 1e-6) {
+			t.Errorf("result[%d] = %g; want %g", i, result[i], testCVD[i])
+		}
+	}
+
+	// Without points below 0 °C the C term is not fitted.
+	var warm []models.ThermistorPoint
+	for _, p := range cvdTestPoints() {
+		if p.Temp >= 0 {
+			warm = append(warm, p)
+		}
+	}
+
+	result, err = FindCVDCoefficients(warm)
+	if err != nil {
+		t.Fatalf("FindCVDCoefficients returned error: %v", err)
+	}
+	if result[3] != 0 {
+		t.Errorf("C = %g; want 0 without points below 0 °C", result[3])
+	}
+	if !floatAlmostEqualPercentage(result[2], testCVD[2], 1e-6) {
+		t.Errorf("B = %g; want %g", result[2], testCVD[2])
+	}
+}
+
+func TestFindCVDCoefficients_NTC(t *testing.T) {
+	if _, err := FindCVDCoefficients(betaTestPoints()); err == nil {
+		t.Errorf("expected error fitting an NTC table")
+	}
+}
+
+func TestFindCVDR0(t *testing.T) {
+	var points []models.ThermistorPoint
+	for _, p := range cvdTestPoints() {
+		p.Resistance /= 10
+		points = append(points, p)
+	}
+
+	result, err := FindCVDR0(points, IECCVD)
+	if err != nil {
+		t.Fatalf("FindCVDR0 returned error: %v", err)
+	}
+	if !floatAlmostEqual(result[0], 100, 1e-9) {
+		t.Errorf("R0 = %f; want 100", result[0])
+	}
+}
+
+func TestGenerateModelLUT_PTC(t *testing.T) {
+	cfg := models.Config{
+		LUTSize:        64,
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             1,
+		UpperLimitTemp: 200,
+		LowerLimitTemp: -50,
+		Sensor:         models.SensorRTD,
+	}
+
+	for sensor, want := range map[string]bool{models.SensorRTD: true, models.SensorPTC: true, models.SensorNTC: false} {
+		if got := IsPTC(models.Config{Sensor: sensor}); got != want {
+			t.Fatalf("IsPTC(%s) = %v; want %v", sensor, got, want)
+		}
+	}
+
+	temps, _, _, err := GenerateModelLUT(cfg, CVDModel(testCVD))
+	if err != nil {
+		t.Fatalf("GenerateModelLUT returned error: %v", err)
+	}
+
+	if temps[0] != cfg.LowerLimitTemp || temps[cfg.LUTSize-1] != cfg.UpperLimitTemp {
+		t.Errorf("endpoints = %f, %f; want %f, %f", temps[0], temps[cfg.LUTSize-1], cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	}
+
+	for i := 1; i < len(temps); i++ {
+		if temps[i] < temps[i-1] {
+			t.Errorf("LUT not rising at index %d: %f < %f", i, temps[i], temps[i-1])
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Closest two calibration temperatures (K) can be before the solution is flagged.
+const exactMinSpacing float64 = 10.0
+
+// Condition number of the column-scaled system above which the solution is flagged.
+const exactMaxCondition float64 = 1e5
+
+// SolveSteinhartExact solves the 3x3 Steinhart-Hart system through exactly three points.
+// Warnings are returned when the points are close together or the system is ill-conditioned.
+func SolveSteinhartExact(points []models.ThermistorPoint) ([3]float64, []string, error) {
+	var result [3]float64
+	var warnings []string
+
+	if len(points) != 3 {
+		return result, nil, fmt.Errorf("exact solve needs exactly 3 points, got %d", len(points))
+	}
+
+	A := mat.NewDense(3, 3, nil)
+	y := mat.NewVecDense(3, nil)
+	for i, p := range points {
+		lnR := math.Log(p.Resistance)
+		A.SetRow(i, []float64{1, lnR, lnR * lnR * lnR})
+		y.SetVec(i, 1/(p.Temp+KelvinToCelsius))
+	}
+
+	for i := 0; i < 3; i++ {
+		for j := i + 1; j < 3; j++ {
+			if spacing := math.Abs(points[i].Temp - points[j].Temp); spacing < exactMinSpacing {
+				warnings = append(warnings, fmt.Sprintf("Calibration points %.2f °C and %.2f °C are only %.2f K apart. Coefficients will be sensitive to measurement error.", points[i].Temp, points[j].Temp, spacing))
+			}
+		}
+	}
+
+	// Scale columns to unit norm so the condition number reflects the point spacing
+	// rather than the magnitude of ln(R)³.
+	scaled := mat.DenseCopyOf(A)
+	for j := 0; j < 3; j++ {
+		col := mat.Col(nil, j, scaled)
+		norm := mat.Norm(mat.NewVecDense(3, col), 2)
+		for i := range col {
+			scaled.Set(i, j, col[i]/norm)
+		}
+	}
+	cond := mat.Cond(scaled, 2)
+	if cond > exactMaxCondition {
+		warnings = append(warnings, fmt.Sprintf("Exact system is ill-conditioned (condition number %.3g). Spread the calibration temperatures further apart.", cond))
+	}
+
+	var coeffs mat.VecDense
+	if err := coeffs.SolveVec(A, y); err != nil {
+		return result, warnings, fmt.Errorf("exact solve failed: %v", err)
+	}
+
+	for i := range result {
+		result[i] = coeffs.AtVec(i)
+	}
+	return result, warnings, nil
+}
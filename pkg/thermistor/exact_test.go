@@ -0,0 +1,57 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestSolveSteinhartExact(t *testing.T) {
+	result, warnings, err := SolveSteinhartExact(testPoints)
+	if err != nil {
+		t.Fatalf("SolveSteinhartExact returned error: %v", err)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings for well spaced points: %v", warnings)
+	}
+
+	for i := range testSteinhartCoeff {
+		if !floatAlmostEqualPercentage(result[i], testSteinhartCoeff[i], 1e-6) {
+			t.Errorf("result[%d] = %g; want %g", i, result[i], testSteinhartCoeff[i])
+		}
+	}
+
+	_, maxDev, _ := CheckDeviation(testPoints, result)
+	if maxDev > 1e-9 {
+		t.Errorf("exact solution deviates by %.3g K", maxDev)
+	}
+}
+
+func TestSolveSteinhartExact_CloseWarning(t *testing.T) {
+	points := []models.ThermistorPoint{
+		{Temp: 24, Resistance: 10450},
+		{Temp: 25, Resistance: 10000},
+		{Temp: 26, Resistance: 9572},
+	}
+
+	_, warnings, err := SolveSteinhartExact(points)
+	if err != nil {
+		t.Fatalf("SolveSteinhartExact returned error: %v", err)
+	}
+
+	if len(warnings) == 0 {
+		t.Errorf("expected warnings for points 1 K apart")
+	}
+}
+
+func TestSolveSteinhartExact_Errors(t *testing.T) {
+	if _, _, err := SolveSteinhartExact(testPoints[:2]); err == nil {
+		t.Errorf("expected error for 2 points")
+	}
+
+	duplicate := []models.ThermistorPoint{testPoints[0], testPoints[0], testPoints[1]}
+	if _, _, err := SolveSteinhartExact(duplicate); err == nil {
+		t.Errorf("expected error for singular system")
+	}
+}
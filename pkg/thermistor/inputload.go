@@ -0,0 +1,83 @@
+package thermistor
+
+import (
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// inputLoaded reports whether cfg has a source resistor, input resistance or leakage between the
+// circuit and the ADC pin.
+func inputLoaded(cfg models.Config) bool {
+	return cfg.RSource != 0 || cfg.RInput != 0 || cfg.Leakage != 0
+}
+
+// sourceResistance returns the Thévenin resistance (Ω) of the circuit output for a thermistor
+// resistance (Ω): the network itself for a current source, else the network in parallel with the
+// series resistor.
+func sourceResistance(cfg models.Config, resistance float64) float64 {
+	rNet := resistance
+	if rp := cfg.RP * 1000; rp != 0 {
+		rNet = resistance * rp / (resistance + rp)
+	}
+
+	if cfg.Topology == models.TopologyCurrent {
+		return rNet
+	}
+	rs := cfg.RS * 1000
+	return rs * rNet / (rs + rNet)
+}
+
+// loadedRatio returns ratio, the open circuit output of the circuit as a fraction of the ADC
+// reference, as read at the ADC pin. The output drives the pin through the source resistor, and
+// the input resistance and leakage current load it:
+//
+//	Vpin = (Vout - Ileak·(Rth + Rsource)) · Rin / (Rth + Rsource + Rin)
+func loadedRatio(cfg models.Config, resistance, ratio float64) float64 {
+	if !inputLoaded(cfg) {
+		return ratio
+	}
+
+	vRef := models.ADCReference(cfg)
+	r := sourceResistance(cfg, resistance) + cfg.RSource*1000
+
+	v := ratio*vRef - cfg.Leakage*1e-9*r
+	if cfg.RInput != 0 {
+		rIn := cfg.RInput * 1000
+		v *= rIn / (r + rIn)
+	}
+	return v / vRef
+}
+
+// InputLoadError scans every ADC code whose loaded temperature lies within the limits of cfg and
+// returns the largest correction (K) the ADC input load makes to the temperature read through
+// model, and the temperature (°C) it occurs at. It is 0 without an input load.
+func InputLoadError(cfg models.Config, model Model) (float64, float64) {
+	if !inputLoaded(cfg) {
+		return 0, 0
+	}
+
+	ideal := cfg
+	ideal.RSource, ideal.RInput, ideal.Leakage = 0, 0, 0
+	return largestCorrection(cfg, ideal, model)
+}
+
+// largestCorrection scans every ADC code whose temperature through cfg lies within its limits
+// and returns the largest difference (K) from the temperature read through ideal, and the
+// temperature (°C) it occurs at.
+func largestCorrection(cfg, ideal models.Config, model Model) (float64, float64) {
+	var worst, worstTemp float64
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+
+	for adc := uint(1); adc < adcMax; adc++ {
+		temp := model(resistanceFromADCValue(cfg, adc)) - KelvinToCelsius
+		if math.IsNaN(temp) || temp < cfg.LowerLimitTemp || temp > cfg.UpperLimitTemp {
+			continue
+		}
+
+		if d := math.Abs(temp - (model(resistanceFromADCValue(ideal, adc)) - KelvinToCelsius)); d > worst {
+			worst, worstTemp = d, temp
+		}
+	}
+	return worst, worstTemp
+}
@@ -0,0 +1,82 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestLoadedRatio(t *testing.T) {
+	cfg := models.Config{VoltageRef: 3.3, RS: 10, RSource: 10, RInput: 5000, Leakage: 50}
+
+	// 10k matched divider: 5k source, 10k protection resistor, 50 nA and a 5 MΩ load.
+	want := (1.65 - 50e-9*15000) * 5e6 / (5e6 + 15000) / 3.3
+	if got := loadedRatio(cfg, 10000, 0.5); !floatAlmostEqual(got, want, 1e-12) {
+		t.Errorf("loadedRatio = %g; want %g", got, want)
+	}
+
+	cfg.RSource, cfg.RInput, cfg.Leakage = 0, 0, 0
+	if got := loadedRatio(cfg, 10000, 0.5); got != 0.5 {
+		t.Errorf("unloaded ratio = %g; want 0.5", got)
+	}
+}
+
+func TestResistanceFromADCValue_InputLoad(t *testing.T) {
+	for _, topology := range []string{models.TopologyLow, models.TopologyHigh, models.TopologyCurrent, models.TopologyBridge} {
+		cfg := models.Config{
+			ADCResolution: 16,
+			VoltageRef:    3.3,
+			RS:            10,
+			Topology:      topology,
+			Excitation:    100,
+			BridgeTop:     10,
+			BridgeBottom:  10,
+			AmpGain:       1,
+			RSource:       10,
+			RInput:        5000,
+			Leakage:       50,
+		}
+
+		for _, r := range []float64{2000, 10000, 25000} {
+			adc := ADCValueFromResistance(cfg, r)
+			if back := resistanceFromADCValue(cfg, adc); !floatAlmostEqualPercentage(back, r, 0.002) {
+				t.Errorf("%s: resistance %g -> ADC %d -> %g", topology, r, adc, back)
+			}
+		}
+	}
+}
+
+func TestInputLoadError(t *testing.T) {
+	model := SteinhartModel(testSteinhartCoeff)
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+	}
+
+	if worst, _ := InputLoadError(cfg, model); worst != 0 {
+		t.Errorf("unloaded correction = %g; want 0", worst)
+	}
+
+	// The input resistance loads the high source resistance of a cold NTC most.
+	cfg.RSource, cfg.RInput = 10, 1000
+	worst, at := InputLoadError(cfg, model)
+	if worst <= 0 || at > 0 {
+		t.Errorf("correction = %g K at %g °C; want a positive correction at the cold end", worst, at)
+	}
+
+	ideal, _, _, err := GenerateLUT(models.Config{ADCResolution: 12, VoltageRef: 3.3, RS: 10, LUTSize: 64, UpperLimitTemp: 125, LowerLimitTemp: -40}, testSteinhartCoeff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.LUTSize = 64
+	loaded, _, _, err := GenerateLUT(cfg, testSteinhartCoeff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded[56] >= ideal[56] {
+		t.Errorf("loaded LUT at the cold end = %g °C; want below the unloaded %g °C", loaded[56], ideal[56])
+	}
+}
@@ -0,0 +1,98 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+const inverseMinResistance float64 = 0.1
+const inverseScanSteps int = 400
+const inverseIterations int = 200
+
+// SteinhartResistance solves the Steinhart-Hart cubic in ln(R) for the resistance (Ω) at tempK.
+func SteinhartResistance(tempK float64, coeff [3]float64) float64 {
+	x := (coeff[0] - 1/tempK) / coeff[2]
+	y := math.Sqrt(math.Pow(coeff[1]/(3*coeff[2]), 3) + x*x/4)
+	return math.Exp(math.Cbrt(y-x/2) - math.Cbrt(y+x/2))
+}
+
+// BetaResistance returns the resistance (Ω) at tempK for beta = {R25, B}.
+func BetaResistance(tempK float64, beta [2]float64) float64 {
+	t0 := models.BetaReferenceTemp + KelvinToCelsius
+	return beta[0] * math.Exp(beta[1]*(1/tempK-1/t0))
+}
+
+// ResistanceFromTemperature numerically inverts any model, returning the resistance (Ω) at
+// tempK. It scans ln(R) for a sign change and then bisects, so it works for polynomial and
+// segmented fits without a closed form.
+func ResistanceFromTemperature(model Model, tempK float64) (float64, error) {
+	lo, hi := math.Log(inverseMinResistance), math.Log(models.ResistanceMax)
+	f := func(lnR float64) float64 { return model(math.Exp(lnR)) - tempK }
+
+	// A physical temperature is finite and above 0 K; this also skips the pole where
+	// the polynomial in 1/T crosses zero.
+	valid := func(v float64) bool {
+		return !math.IsNaN(v) && !math.IsInf(v, 0) && v+tempK > 0
+	}
+
+	step := (hi - lo) / float64(inverseScanSteps)
+	a, fa := lo, f(lo)
+	found := false
+	for i := 1; i <= inverseScanSteps; i++ {
+		b := lo + float64(i)*step
+		fb := f(b)
+		if valid(fa) && valid(fb) && (fa == 0 || fa*fb < 0) {
+			hi = b
+			found = true
+			break
+		}
+		a, fa = b, fb
+	}
+	if !found {
+		return 0, fmt.Errorf("%.2f °C is outside the range of the model", tempK-KelvinToCelsius)
+	}
+
+	lo = a
+	for i := 0; i < inverseIterations && fa != 0; i++ {
+		mid := (lo + hi) / 2
+		fm := f(mid)
+		if fm == 0 {
+			lo, hi = mid, mid
+			break
+		}
+		if (fm < 0) == (fa < 0) {
+			lo, fa = mid, fm
+		} else {
+			hi = mid
+		}
+	}
+
+	return math.Exp((lo + hi) / 2), nil
+}
+
+// ADCValueFromResistance returns the ADC code the divider produces for a thermistor
+// resistance (Ω), the inverse of resistanceFromADCValue.
+func ADCValueFromResistance(cfg models.Config, resistance float64) uint {
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+
+	// Readings beyond either end of the ADC range clip, as an amplified bridge does.
+	code := math.Round(adcCode(cfg, resistance))
+	if code < 0 {
+		return 0
+	}
+	if code > float64(adcMax) {
+		return adcMax
+	}
+	return uint(code)
+}
+
+// ADCValueFromTemperature returns the expected ADC code and thermistor resistance at tempC.
+func ADCValueFromTemperature(cfg models.Config, model Model, tempC float64) (uint, float64, error) {
+	resistance, err := ResistanceFromTemperature(model, tempC+KelvinToCelsius)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ADCValueFromResistance(cfg, resistance), resistance, nil
+}
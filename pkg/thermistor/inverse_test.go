@@ -0,0 +1,130 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestSteinhartResistance(t *testing.T) {
+	for _, row := range testPoints {
+		r := SteinhartResistance(row.Temp+KelvinToCelsius, testSteinhartCoeff)
+		if !floatAlmostEqualPercentage(r, row.Resistance, 1e-4) {
+			t.Errorf("SteinhartResistance(%.1f) = %f; want %f", row.Temp, r, row.Resistance)
+		}
+	}
+}
+
+func TestBetaResistance(t *testing.T) {
+	for _, p := range betaTestPoints() {
+		r := BetaResistance(p.Temp+KelvinToCelsius, testBeta)
+		if !floatAlmostEqualPercentage(r, p.Resistance, 1e-9) {
+			t.Errorf("BetaResistance(%.1f) = %f; want %f", p.Temp, r, p.Resistance)
+		}
+	}
+}
+
+func TestResistanceFromTemperature(t *testing.T) {
+	tests := map[string]Model{
+		"steinhart":  SteinhartModel(testSteinhartCoeff),
+		"beta":       BetaModel(testBeta),
+		"polynomial": PolynomialModel(testPolyCoeff),
+	}
+
+	for name, model := range tests {
+		t.Run(name, func(t *testing.T) {
+			for temp := -40.0; temp <= 150; temp += 10 {
+				r, err := ResistanceFromTemperature(model, temp+KelvinToCelsius)
+				if err != nil {
+					t.Fatalf("ResistanceFromTemperature(%.1f) returned error: %v", temp, err)
+				}
+				if back := model(r) - KelvinToCelsius; !floatAlmostEqual(back, temp, 1e-6) {
+					t.Errorf("round trip %.1f °C -> %f Ω -> %f °C", temp, r, back)
+				}
+			}
+		})
+	}
+
+	r, err := ResistanceFromTemperature(SteinhartModel(testSteinhartCoeff), 25+KelvinToCelsius)
+	if err != nil {
+		t.Fatalf("ResistanceFromTemperature returned error: %v", err)
+	}
+	if want := SteinhartResistance(25+KelvinToCelsius, testSteinhartCoeff); !floatAlmostEqualPercentage(r, want, 1e-9) {
+		t.Errorf("numeric inverse %f does not match closed form %f", r, want)
+	}
+
+	if _, err := ResistanceFromTemperature(BetaModel(testBeta), 1e6); err == nil {
+		t.Errorf("expected error for temperature outside the model range")
+	}
+}
+
+func TestADCValueFromResistance(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution: 12,
+		VoltageRef:    3.3,
+		RS:            10,
+		RP:            100,
+	}
+
+	for _, adc := range []uint{100, 1000, 2048, 3000, 3700} {
+		r := getResistanceFromADCValue(cfg.VoltageRef, cfg.VoltageRef, adc, cfg.ADCResolution, cfg.RS*1000, cfg.RP*1000)
+		if back := ADCValueFromResistance(cfg, r); back != adc {
+			t.Errorf("round trip ADC %d -> %f Ω -> ADC %d", adc, r, back)
+		}
+	}
+
+	// The 100k parallel resistor alone reads about 3723, so higher codes are out of range.
+	if r := getResistanceFromADCValue(cfg.VoltageRef, cfg.VoltageRef, 4000, cfg.ADCResolution, cfg.RS*1000, cfg.RP*1000); r != models.ResistanceMax {
+		t.Errorf("ADC 4000 beyond the parallel resistor = %f Ω, want %g", r, models.ResistanceMax)
+	}
+
+	cfg.RP = 0
+	if adc := ADCValueFromResistance(cfg, models.ResistanceMax*10); adc != 4095 {
+		t.Errorf("open circuit ADC = %d, want 4095", adc)
+	}
+}
+
+func TestADCValueFromTemperature(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution: 12,
+		VoltageRef:    3.3,
+		RS:            10,
+	}
+
+	adc, r, err := ADCValueFromTemperature(cfg, SteinhartModel(testSteinhartCoeff), 25)
+	if err != nil {
+		t.Fatalf("ADCValueFromTemperature returned error: %v", err)
+	}
+
+	// 10k thermistor against a 10k series resistor sits at mid scale.
+	if !floatAlmostEqualPercentage(r, 10000, 1e-3) || adc != 2048 {
+		t.Errorf("got ADC %d, %f Ω; want 2048, 10000 Ω", adc, r)
+	}
+}
+
+func TestADCValueFromResistance_BridgeClip(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution: 12,
+		VoltageRef:    3.3,
+		RS:            10,
+		Topology:      models.TopologyBridge,
+		BridgeTop:     10,
+		BridgeBottom:  10,
+		AmpGain:       10,
+	}
+
+	// With gain the amplified bridge overdrives the ADC at both ends of the resistance range.
+	for _, r := range []float64{100, 1000, 5000, 10000, 20000, 100000, 1e6} {
+		adc := ADCValueFromResistance(cfg, r)
+		if code := models.SignedADCValue(cfg, adc); code < -2048 || code > 2047 {
+			t.Errorf("resistance %g reads %d; want within -2048..2047", r, code)
+		}
+	}
+
+	if adc := ADCValueFromResistance(cfg, 100); adc != 0 {
+		t.Errorf("low resistance reads ADC %d; want 0, the most negative code", adc)
+	}
+	if adc := ADCValueFromResistance(cfg, 1e6); adc != 4095 {
+		t.Errorf("high resistance reads ADC %d; want 4095", adc)
+	}
+}
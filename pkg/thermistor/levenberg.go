@@ -0,0 +1,163 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"gonum.org/v1/gonum/mat"
+)
+
+const lmMaxIterations int = 200
+const lmInitialLambda float64 = 1e-3
+const lmTolerance float64 = 1e-12
+
+// LMResult holds the convergence diagnostics of a Levenberg-Marquardt refinement.
+// Costs are weighted sums of squared temperature residuals (K²).
+type LMResult struct {
+	Iterations  int
+	Converged   bool
+	InitialCost float64
+	FinalCost   float64
+	Lambda      float64
+	Points      int
+}
+
+// InitialRMS and FinalRMS are the root mean square residuals (K) before and after refinement.
+func (r LMResult) InitialRMS() float64 { return math.Sqrt(r.InitialCost / float64(r.Points)) }
+func (r LMResult) FinalRMS() float64   { return math.Sqrt(r.FinalCost / float64(r.Points)) }
+
+// RefineLevenbergMarquardt minimises the weighted squared residuals in °C of the
+// ln(R) polynomial over the given powers, starting from the dense seed coefficients
+// (typically the linear 1/T least squares solution). Only points between lower and
+// upper are used; pass ±Inf to use every point.
+func RefineLevenbergMarquardt(points []models.ThermistorPoint, seed []float64, powers []int, lower, upper float64) ([]float64, LMResult, error) {
+	var result LMResult
+
+	inRange := PointsInRange(points, lower, upper)
+	if len(inRange) < len(powers) {
+		return nil, result, fmt.Errorf("not enough points between %.1f and %.1f °C for a nonlinear fit (%d, need %d)", lower, upper, len(inRange), len(powers))
+	}
+	result.Points = len(inRange)
+
+	weights := pointWeights(inRange)
+	rows := make([][]float64, len(inRange))
+	for i, p := range inRange {
+		rows[i] = lnPolynomialRow(p.Resistance, powers)
+	}
+
+	params := make([]float64, len(powers))
+	for i, p := range powers {
+		if p < len(seed) {
+			params[i] = seed[p]
+		}
+	}
+
+	cost := func(params []float64) float64 {
+		var sum float64
+		for i, p := range inRange {
+			r := lmResidual(rows[i], params, p.Temp)
+			sum += lmWeight(weights, i) * r * r
+		}
+		return sum
+	}
+
+	n := len(powers)
+	lambda := lmInitialLambda
+	current := cost(params)
+	result.InitialCost = current
+
+	for result.Iterations = 0; result.Iterations < lmMaxIterations; result.Iterations++ {
+		JtJ := mat.NewSymDense(n, nil)
+		Jtr := mat.NewVecDense(n, nil)
+
+		for i, p := range inRange {
+			s := lmSum(rows[i], params)
+			r := 1/s - KelvinToCelsius - p.Temp
+			w := lmWeight(weights, i)
+
+			for a := 0; a < n; a++ {
+				ja := -rows[i][a] / (s * s)
+				Jtr.SetVec(a, Jtr.AtVec(a)+w*ja*r)
+				for b := a; b < n; b++ {
+					jb := -rows[i][b] / (s * s)
+					JtJ.SetSym(a, b, JtJ.At(a, b)+w*ja*jb)
+				}
+			}
+		}
+
+		improved := false
+		for !improved && lambda < 1e12 {
+			A := mat.NewDense(n, n, nil)
+			A.Copy(JtJ)
+			for a := 0; a < n; a++ {
+				A.Set(a, a, JtJ.At(a, a)*(1+lambda))
+			}
+
+			var step mat.VecDense
+			if err := step.SolveVec(A, Jtr); err != nil {
+				lambda *= 10
+				continue
+			}
+
+			trial := make([]float64, n)
+			for k := range params {
+				trial[k] = params[k] - step.AtVec(k)
+			}
+
+			trialCost := cost(trial)
+			if trialCost < current {
+				improved = true
+				converged := current-trialCost <= lmTolerance*current
+				params, current = trial, trialCost
+				lambda /= 10
+				if converged {
+					result.Converged = true
+				}
+			} else {
+				lambda *= 10
+			}
+		}
+
+		if !improved {
+			// No step reduces the cost any further; the seed or last step is a minimum.
+			result.Converged = true
+		}
+		if result.Converged {
+			break
+		}
+	}
+
+	result.FinalCost = current
+	result.Lambda = lambda
+
+	maxPower := 0
+	for _, p := range powers {
+		maxPower = max(maxPower, p)
+	}
+
+	coeffs := make([]float64, maxPower+1)
+	for i, p := range powers {
+		coeffs[p] = params[i]
+	}
+	return coeffs, result, nil
+}
+
+func lmSum(row, params []float64) float64 {
+	var s float64
+	for k := range params {
+		s += params[k] * row[k]
+	}
+	return s
+}
+
+func lmResidual(row, params []float64, temp float64) float64 {
+	return 1/lmSum(row, params) - KelvinToCelsius - temp
+}
+
+func lmWeight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1.0
+	}
+	return weights[i]
+}
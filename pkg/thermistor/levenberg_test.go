@@ -0,0 +1,71 @@
+package thermistor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRefineLevenbergMarquardt(t *testing.T) {
+	points := polyTestPoints()
+
+	seed, err := FindLnPolynomialCoefficients(points, SteinhartPowers)
+	if err != nil {
+		t.Fatalf("FindLnPolynomialCoefficients returned error: %v", err)
+	}
+
+	refined, diag, err := RefineLevenbergMarquardt(points, seed, SteinhartPowers, math.Inf(-1), math.Inf(1))
+	if err != nil {
+		t.Fatalf("RefineLevenbergMarquardt returned error: %v", err)
+	}
+
+	if !diag.Converged {
+		t.Errorf("did not converge after %d iterations", diag.Iterations)
+	}
+	if diag.FinalCost > diag.InitialCost {
+		t.Errorf("final cost %.3g above initial cost %.3g", diag.FinalCost, diag.InitialCost)
+	}
+	if diag.Points != len(points) {
+		t.Errorf("used %d points, want %d", diag.Points, len(points))
+	}
+	if refined[2] != 0 {
+		t.Errorf("refinement used ln²R term outside the basis: %v", refined)
+	}
+
+	var sum float64
+	for _, p := range points {
+		dev := PolynomialCalculation(p.Resistance, refined) - KelvinToCelsius - p.Temp
+		sum += dev * dev
+	}
+	if !floatAlmostEqualPercentage(sum, diag.FinalCost, 1e-9) {
+		t.Errorf("final cost %.6g does not match residuals %.6g", diag.FinalCost, sum)
+	}
+}
+
+func TestRefineLevenbergMarquardt_RecoversExact(t *testing.T) {
+	points := polyTestPoints()
+
+	// Seed away from the exact solution the points were generated from.
+	seed := []float64{testPolyCoeff[0] * 1.01, testPolyCoeff[1] * 0.99, testPolyCoeff[2], testPolyCoeff[3]}
+
+	refined, diag, err := RefineLevenbergMarquardt(points, seed, PolynomialPowers(3), math.Inf(-1), math.Inf(1))
+	if err != nil {
+		t.Fatalf("RefineLevenbergMarquardt returned error: %v", err)
+	}
+
+	if diag.FinalRMS() > 1e-4 {
+		t.Errorf("final RMS = %.3g K, want ~0", diag.FinalRMS())
+	}
+
+	_, maxDev, _ := CheckPolynomialDeviation(points, refined)
+	if maxDev > 1e-3 {
+		t.Errorf("max deviation = %.3g, want ~0", maxDev)
+	}
+}
+
+func TestRefineLevenbergMarquardt_Range(t *testing.T) {
+	seed := SteinhartToPolynomial(testSteinhartCoeff)
+
+	if _, _, err := RefineLevenbergMarquardt(testPoints, seed, SteinhartPowers, 0, 25); err == nil {
+		t.Errorf("expected error for too few points in range")
+	}
+}
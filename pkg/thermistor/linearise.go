@@ -0,0 +1,128 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// Number of temperatures the linearity of a divider is evaluated at.
+const lineariseGridPoints int = 101
+
+// Linearisation describes a divider whose ADC code is read as temperature (°C) = Gain·adc + Offset.
+type Linearisation struct {
+	RS       float64 // Ω
+	RP       float64 // Ω, 0 = none
+	Gain     float64 // °C/LSB
+	Offset   float64 // °C
+	MaxError float64 // K, largest deviation of the line from the model between the limits
+	MaxAt    float64 // °C, temperature of MaxError
+}
+
+// LinearityError fits a straight line of temperature against ADC code to the divider of rs
+// and rp (Ω) between the temperature limits of cfg and returns it with its worst deviation.
+func LinearityError(cfg models.Config, model Model, rs, rp float64) (Linearisation, error) {
+	temps, resistances, err := sampleResistances(cfg, model, lineariseGridPoints)
+	if err != nil {
+		return Linearisation{}, err
+	}
+	return linearFit(cfg.Topology, temps, resistances, float64(uint(1)<<cfg.ADCResolution)*supplyRatio(cfg), rs, rp), nil
+}
+
+// LineariseDivider searches resistors of the given E series for the divider whose ADC code is
+// most linear in temperature between the limits of cfg, so the firmware can read it with a
+// single multiply and add. The series resistor and parallel resistor act as a source of
+// resistance RS‖RP, so the linearity depends only on that: with rs 0 the series resistor is
+// searched without a parallel resistor, which keeps the full code span, and with rs (Ω) given the
+// parallel resistor that brings RS‖RP closest to the most linear value is searched instead.
+func LineariseDivider(cfg models.Config, model Model, series string, rs float64) (Linearisation, error) {
+	values, err := ESeriesValues(series, optimiseMinResistance, optimiseMaxResistance)
+	if err != nil {
+		return Linearisation{}, err
+	}
+
+	temps, resistances, err := sampleResistances(cfg, model, lineariseGridPoints)
+	if err != nil {
+		return Linearisation{}, err
+	}
+	// ADC codes per unit divider ratio.
+	codes := float64(uint(1)<<cfg.ADCResolution) * supplyRatio(cfg)
+
+	best := Linearisation{MaxError: math.Inf(1)}
+	for _, v := range values {
+		l := linearFit(cfg.Topology, temps, resistances, codes, v, 0)
+		if rs != 0 {
+			l = linearFit(cfg.Topology, temps, resistances, codes, rs, v)
+		}
+		if l.MaxError < best.MaxError {
+			best = l
+		}
+	}
+
+	if math.IsInf(best.MaxError, 1) {
+		return best, fmt.Errorf("no divider is linear between %.1f and %.1f °C", cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	}
+	return best, nil
+}
+
+// sampleResistances returns n temperatures (°C) evenly spaced between the limits of cfg and
+// the model resistance (Ω) at each.
+func sampleResistances(cfg models.Config, model Model, n int) ([]float64, []float64, error) {
+	if cfg.UpperLimitTemp <= cfg.LowerLimitTemp {
+		return nil, nil, fmt.Errorf("upper temperature limit must be above the lower limit")
+	}
+
+	var err error
+	temps := make([]float64, n)
+	resistances := make([]float64, n)
+	for i := range temps {
+		temps[i] = cfg.LowerLimitTemp + (cfg.UpperLimitTemp-cfg.LowerLimitTemp)*float64(i)/float64(n-1)
+		resistances[i], err = ResistanceFromTemperature(model, temps[i]+KelvinToCelsius)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return temps, resistances, nil
+}
+
+// linearFit returns the least squares slope of temps against the ideal ADC codes of resistances
+// through the divider of rs and rp, with the offset that minimises its largest deviation. codes
+// is the ADC code of the divider supply.
+func linearFit(topology string, temps, resistances []float64, codes, rs, rp float64) Linearisation {
+	n := float64(len(temps))
+	x := make([]float64, len(temps))
+
+	var sx, sy, sxx, sxy float64
+	for i, r := range resistances {
+		x[i] = codes * dividerRatio(topology, r, rs, rp)
+		sx += x[i]
+		sy += temps[i]
+		sxx += x[i] * x[i]
+		sxy += x[i] * temps[i]
+	}
+
+	l := Linearisation{RS: rs, RP: rp, MaxError: math.Inf(1)}
+	det := n*sxx - sx*sx
+	if det <= 0 {
+		return l
+	}
+	l.Gain = (n*sxy - sx*sy) / det
+	l.Offset = (sy - l.Gain*sx) / n
+
+	// Centre the error band, which lowers the worst deviation of the least squares line.
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i, t := range temps {
+		e := l.Gain*x[i] + l.Offset - t
+		lo, hi = math.Min(lo, e), math.Max(hi, e)
+	}
+	l.Offset -= (lo + hi) / 2
+
+	l.MaxError = 0
+	for i, t := range temps {
+		if e := math.Abs(l.Gain*x[i] + l.Offset - t); e > l.MaxError {
+			l.MaxError, l.MaxAt = e, t
+		}
+	}
+	return l
+}
@@ -0,0 +1,57 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestLineariseDivider(t *testing.T) {
+	cfg := models.Config{ADCResolution: 12, LowerLimitTemp: 0, UpperLimitTemp: 50}
+	model := BetaModel(testBeta)
+
+	best, err := LineariseDivider(cfg, model, ESeriesNone, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if best.RP != 0 {
+		t.Errorf("RP = %g Ω; want none when the series resistor is free", best.RP)
+	}
+
+	// Textbook value for the inflection of the divider at the middle of the range.
+	tm := 25 + KelvinToCelsius
+	rm, _ := ResistanceFromTemperature(model, tm)
+	want := rm * (testBeta[1] - 2*tm) / (testBeta[1] + 2*tm)
+	if !floatAlmostEqualPercentage(best.RS, want, 0.1) {
+		t.Errorf("RS = %.0f Ω; want about %.0f Ω", best.RS, want)
+	}
+
+	r25, err := LinearityError(cfg, model, 10000, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if best.MaxError >= r25.MaxError {
+		t.Errorf("linearised error %g K should be below %g K with RS = R25", best.MaxError, r25.MaxError)
+	}
+
+	// With the series resistor held, RS‖RP lands on the same source resistance.
+	held, err := LineariseDivider(cfg, model, ESeriesNone, 100000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rth := held.RS * held.RP / (held.RS + held.RP); !floatAlmostEqualPercentage(rth, best.RS, 0.03) {
+		t.Errorf("RS‖RP = %.0f Ω; want about %.0f Ω", rth, best.RS)
+	}
+	if !floatAlmostEqualPercentage(held.MaxError, best.MaxError, 0.05) {
+		t.Errorf("error with RS held = %g K; want about %g K", held.MaxError, best.MaxError)
+	}
+
+	// The line reproduces the model at its best point to within the reported error.
+	adc, _, err := ADCValueFromTemperature(models.Config{ADCResolution: 12, VoltageRef: 3.3, RS: best.RS / 1000}, model, 25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if linear := best.Gain*float64(adc) + best.Offset; !floatAlmostEqual(linear, 25, best.MaxError+0.05) {
+		t.Errorf("linear temperature at 25 °C = %g; want within %g K", linear, best.MaxError)
+	}
+}
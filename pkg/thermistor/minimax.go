@@ -0,0 +1,82 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+const minimaxMaxIterations int = 500
+const minimaxTolerance float64 = 1e-6
+
+// PointsInRange returns the points with lower <= Temp <= upper.
+func PointsInRange(points []models.ThermistorPoint, lower, upper float64) []models.ThermistorPoint {
+	var inRange []models.ThermistorPoint
+	for _, p := range points {
+		if p.Temp >= lower && p.Temp <= upper {
+			inRange = append(inRange, p)
+		}
+	}
+	return inRange
+}
+
+// FindMinimaxCoefficients fits the given powers of ln(R) so that the maximum |deviation|
+// in °C over the points between lower and upper is minimised, using Lawson's iteratively
+// reweighted least squares. It returns dense coefficients and the achieved max deviation.
+func FindMinimaxCoefficients(points []models.ThermistorPoint, powers []int, lower, upper float64) ([]float64, float64, error) {
+	inRange := PointsInRange(points, lower, upper)
+	if len(inRange) < len(powers)+1 {
+		return nil, 0, fmt.Errorf("not enough points between %.1f and %.1f °C for a minimax fit (%d, need %d)", lower, upper, len(inRange), len(powers)+1)
+	}
+
+	// Solving in 1/T space, T⁴ scales the squared residuals to roughly K².
+	scale := make([]float64, len(inRange))
+	lawson := make([]float64, len(inRange))
+	for i, p := range inRange {
+		tK := p.Temp + KelvinToCelsius
+		scale[i] = tK * tK * tK * tK
+		lawson[i] = 1 / float64(len(inRange))
+	}
+
+	var best []float64
+	bestMax := math.Inf(1)
+
+	fitPoints := make([]models.ThermistorPoint, len(inRange))
+	copy(fitPoints, inRange)
+
+	for iter := 0; iter < minimaxMaxIterations; iter++ {
+		for i := range fitPoints {
+			fitPoints[i].Weight = lawson[i] * scale[i]
+		}
+
+		coeff, err := FindLnPolynomialCoefficients(fitPoints, powers)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		_, maxDev, _ := CheckPolynomialDeviation(inRange, coeff)
+		if maxDev < bestMax {
+			if bestMax-maxDev < minimaxTolerance*bestMax {
+				best, bestMax = coeff, maxDev
+				break
+			}
+			best, bestMax = coeff, maxDev
+		}
+
+		var sum float64
+		for i, p := range inRange {
+			dev := math.Abs(PolynomialCalculation(p.Resistance, coeff) - KelvinToCelsius - p.Temp)
+			lawson[i] *= dev
+			sum += lawson[i]
+		}
+		if sum == 0 {
+			break
+		}
+		for i := range lawson {
+			lawson[i] /= sum
+		}
+	}
+
+	return best, bestMax, nil
+}
@@ -0,0 +1,59 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestPointsInRange(t *testing.T) {
+	inRange := PointsInRange(testPoints, 0, 25)
+	if len(inRange) != 2 {
+		t.Fatalf("got %d points, want 2", len(inRange))
+	}
+	if inRange[0].Temp != 0 || inRange[1].Temp != 25 {
+		t.Errorf("unexpected points %v", inRange)
+	}
+}
+
+func TestFindMinimaxCoefficients(t *testing.T) {
+	points := polyTestPoints()
+	inRange := PointsInRange(points, -20, 100)
+
+	lsq, err := FindLnPolynomialCoefficients(inRange, SteinhartPowers)
+	if err != nil {
+		t.Fatalf("FindLnPolynomialCoefficients returned error: %v", err)
+	}
+	_, lsqMaxDev, _ := CheckPolynomialDeviation(inRange, lsq)
+
+	mm, mmMaxDev, err := FindMinimaxCoefficients(points, SteinhartPowers, -20, 100)
+	if err != nil {
+		t.Fatalf("FindMinimaxCoefficients returned error: %v", err)
+	}
+
+	if mm[2] != 0 {
+		t.Errorf("minimax used ln²R term outside the basis: %v", mm)
+	}
+
+	_, checkMaxDev, _ := CheckPolynomialDeviation(inRange, mm)
+	if !floatAlmostEqual(checkMaxDev, mmMaxDev, 1e-12) {
+		t.Errorf("reported max deviation %.3g, CheckPolynomialDeviation gives %.3g", mmMaxDev, checkMaxDev)
+	}
+
+	if mmMaxDev >= lsqMaxDev {
+		t.Errorf("minimax max deviation %.3g not better than least squares %.3g", mmMaxDev, lsqMaxDev)
+	}
+}
+
+func TestFindMinimaxCoefficients_Error(t *testing.T) {
+	points := []models.ThermistorPoint{
+		{Temp: 0, Resistance: 27219},
+		{Temp: 25, Resistance: 10000},
+		{Temp: 50, Resistance: 4161},
+		{Temp: 100, Resistance: 973},
+	}
+
+	if _, _, err := FindMinimaxCoefficients(points, SteinhartPowers, 0, 50); err == nil {
+		t.Errorf("expected error for too few points in range")
+	}
+}
@@ -0,0 +1,156 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Tolerances are treated as 3σ of a normal distribution.
+const toleranceSigmas float64 = 3
+
+// MonteCarloResult holds the temperature error (K) of every trial at every temperature,
+// firmware reading minus true temperature, indexed [temperature][trial].
+type MonteCarloResult struct {
+	Temps  []float64
+	Errors [][]float64
+}
+
+// ErrorStats summarises a temperature error distribution. P997 is the 99.7th percentile
+// of the absolute error.
+type ErrorStats struct {
+	Mean  float64
+	Sigma float64
+	P997  float64
+	Max   float64
+}
+
+// MonteCarlo simulates cfg.Trials boards at each of temps (°C). Every trial draws the
+// thermistor R25 and B, the series and parallel resistors, the mismatch between the divider
+// supply and the ADC reference, an INL bow and per-reading ADC noise, then converts the
+// resulting ADC code to temperature the way the firmware does: through lut if given, else
+// through the model. Trials run concurrently and each is seeded from cfg.Seed, so results
+// are reproducible.
+func MonteCarlo(cfg models.Config, model Model, lut []float64, temps []float64) (MonteCarloResult, error) {
+	result := MonteCarloResult{Temps: temps, Errors: make([][]float64, len(temps))}
+
+	if cfg.Trials <= 0 {
+		return result, fmt.Errorf("monte carlo needs at least 1 trial")
+	}
+
+	r25, err := ResistanceFromTemperature(model, models.BetaReferenceTemp+KelvinToCelsius)
+	if err != nil {
+		return result, err
+	}
+
+	nominal := make([]float64, len(temps))
+	for i, t := range temps {
+		nominal[i], err = ResistanceFromTemperature(model, t+KelvinToCelsius)
+		if err != nil {
+			return result, err
+		}
+		result.Errors[i] = make([]float64, cfg.Trials)
+	}
+
+	bSpread := cfg.TolB
+	if IsPTC(cfg) {
+		bSpread = 0
+	}
+
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+	var lutShift uint
+	if len(lut) != 0 {
+		lutShift = cfg.ADCResolution - uint(math.Log2(float64(len(lut))))
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for trial := w; trial < cfg.Trials; trial += workers {
+				rng := rand.New(rand.NewSource(cfg.Seed + int64(trial)))
+				spread := func(tol float64) float64 {
+					return rng.NormFloat64() * tol / (100 * toleranceSigmas)
+				}
+
+				dR, dB := spread(cfg.TolR25), spread(bSpread)
+				board := cfg
+				board.RS = cfg.RS * (1 + spread(cfg.TolRS))
+				board.RP = cfg.RP * (1 + spread(cfg.TolRP))
+				refRatio := 1 + spread(cfg.VrefTol)
+				inl := cfg.ADCINL * (2*rng.Float64() - 1)
+
+				for i, t := range temps {
+					resistance := r25 * (1 + dR) * math.Exp(math.Log(nominal[i]/r25)*(1+dB))
+
+					code := adcTransfer(cfg, codeFromRatio(cfg, circuitRatio(board, resistance)/refRatio))
+					code += inl*math.Sin(math.Pi*code/float64(adcMax+1)) + cfg.ADCNoise*rng.NormFloat64()
+					adc := uint(math.Min(math.Max(math.Round(code), 0), float64(adcMax)))
+
+					var reading float64
+					if len(lut) != 0 {
+						reading = lut[adc>>lutShift]
+					} else {
+						r := resistanceFromADCValue(cfg, adc)
+						reading = clampTemperature(model(r)-KelvinToCelsius, cfg.UpperLimitTemp, cfg.LowerLimitTemp)
+					}
+
+					result.Errors[i][trial] = reading - t
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// ErrorStatistics returns the mean, standard deviation, 99.7th percentile and maximum of
+// the absolute error of errors (K).
+func ErrorStatistics(errors []float64) ErrorStats {
+	var s ErrorStats
+	s.Mean, s.Sigma = stat.MeanStdDev(errors, nil)
+
+	abs := make([]float64, len(errors))
+	for i, e := range errors {
+		abs[i] = math.Abs(e)
+	}
+	sort.Float64s(abs)
+
+	s.P997 = stat.Quantile(0.997, stat.Empirical, abs, nil)
+	s.Max = abs[len(abs)-1]
+	return s
+}
+
+// ErrorHistogram bins errors (K) into bins equal width bins spanning their range and returns
+// the bin edges (bins+1) and counts.
+func ErrorHistogram(errors []float64, bins int) ([]float64, []float64) {
+	sorted := make([]float64, len(errors))
+	copy(sorted, errors)
+	sort.Float64s(sorted)
+
+	lo, hi := sorted[0], sorted[len(sorted)-1]
+	if hi == lo {
+		lo, hi = lo-0.5, hi+0.5
+	}
+
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = lo + (hi-lo)*float64(i)/float64(bins)
+	}
+	// stat.Histogram needs the top edge above the largest value.
+	edges[bins] = math.Nextafter(hi, math.Inf(1))
+
+	counts := stat.Histogram(nil, edges, sorted, nil)
+	return edges, counts
+}
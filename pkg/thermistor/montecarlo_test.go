@@ -0,0 +1,115 @@
+package thermistor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func monteCarloConfig() models.Config {
+	return models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+		Trials:         2000,
+		Seed:           1,
+	}
+}
+
+func TestMonteCarlo_Ideal(t *testing.T) {
+	cfg := monteCarloConfig()
+	cfg.Trials = 10
+	temps := []float64{0, 25, 50}
+
+	result, err := MonteCarlo(cfg, BetaModel(testBeta), nil, temps)
+	if err != nil {
+		t.Fatalf("MonteCarlo returned error: %v", err)
+	}
+
+	// Without perturbations only the ADC quantisation remains.
+	for i := range temps {
+		s := ErrorStatistics(result.Errors[i])
+		if s.Max > 0.05 || s.Sigma != 0 {
+			t.Errorf("%.0f °C: max error %g, sigma %g; want quantisation only", temps[i], s.Max, s.Sigma)
+		}
+	}
+}
+
+func TestMonteCarlo_Tolerance(t *testing.T) {
+	cfg := monteCarloConfig()
+	cfg.TolR25 = 1
+
+	result, err := MonteCarlo(cfg, BetaModel(testBeta), nil, []float64{25})
+	if err != nil {
+		t.Fatalf("MonteCarlo returned error: %v", err)
+	}
+
+	// 1% at 3σ is 0.33% of R, dT = dR/R * T²/B.
+	tK := 25 + KelvinToCelsius
+	want := 0.01 / toleranceSigmas * tK * tK / testBeta[1]
+
+	s := ErrorStatistics(result.Errors[0])
+	if !floatAlmostEqualPercentage(s.Sigma, want, 0.1) {
+		t.Errorf("sigma = %g; want about %g", s.Sigma, want)
+	}
+	if math.Abs(s.Mean) > 3*want/math.Sqrt(float64(cfg.Trials)) {
+		t.Errorf("mean = %g; want about 0", s.Mean)
+	}
+	if s.P997 < 2*want || s.P997 > s.Max {
+		t.Errorf("99.7th percentile = %g, max = %g", s.P997, s.Max)
+	}
+
+	again, err := MonteCarlo(cfg, BetaModel(testBeta), nil, []float64{25})
+	if err != nil {
+		t.Fatalf("MonteCarlo returned error: %v", err)
+	}
+	for trial := range again.Errors[0] {
+		if again.Errors[0][trial] != result.Errors[0][trial] {
+			t.Fatalf("trial %d differs between runs with the same seed", trial)
+		}
+	}
+}
+
+func TestMonteCarlo_LUT(t *testing.T) {
+	cfg := monteCarloConfig()
+	cfg.LUTSize = 256
+	cfg.Trials = 10
+
+	lut, _, _, err := GenerateModelLUT(cfg, BetaModel(testBeta))
+	if err != nil {
+		t.Fatalf("GenerateModelLUT returned error: %v", err)
+	}
+
+	result, err := MonteCarlo(cfg, BetaModel(testBeta), lut, []float64{25})
+	if err != nil {
+		t.Fatalf("MonteCarlo returned error: %v", err)
+	}
+
+	// A 256 entry LUT steps about 0.3 K around 25 °C.
+	if s := ErrorStatistics(result.Errors[0]); s.Max > 0.5 {
+		t.Errorf("LUT error = %g K; want under one LUT step", s.Max)
+	}
+}
+
+func TestErrorHistogram(t *testing.T) {
+	errors := []float64{-1, -0.5, 0, 0, 0.5, 1}
+
+	edges, counts := ErrorHistogram(errors, 4)
+	if len(edges) != 5 || len(counts) != 4 {
+		t.Fatalf("got %d edges and %d counts; want 5 and 4", len(edges), len(counts))
+	}
+
+	var total float64
+	for _, c := range counts {
+		total += c
+	}
+	if total != float64(len(errors)) {
+		t.Errorf("histogram holds %g values; want %d", total, len(errors))
+	}
+	if edges[0] != -1 || !floatAlmostEqual(edges[4], 1, 1e-12) {
+		t.Errorf("edges = %v; want -1..1", edges)
+	}
+}
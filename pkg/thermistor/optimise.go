@@ -0,0 +1,200 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+const (
+	MetricSensitivity  = "sensitivity"
+	MetricQuantisation = "quantisation"
+)
+
+const (
+	ESeriesNone = "none"
+	ESeriesE24  = "e24"
+	ESeriesE96  = "e96"
+)
+
+// Search range (Ω) of the divider resistors and the density of the unsnapped search.
+const (
+	optimiseMinResistance float64 = 100
+	optimiseMaxResistance float64 = 10e6
+	optimisePerDecade     int     = 96
+	optimiseGridPoints    int     = 2000
+	optimiseShortlist     int     = 20
+)
+
+var e24 = []float64{1.0, 1.1, 1.2, 1.3, 1.5, 1.6, 1.8, 2.0, 2.2, 2.4, 2.7, 3.0, 3.3, 3.6, 3.9, 4.3, 4.7, 5.1, 5.6, 6.2, 6.8, 7.5, 8.2, 9.1}
+
+var e96 = []float64{
+	1.00, 1.02, 1.05, 1.07, 1.10, 1.13, 1.15, 1.18, 1.21, 1.24, 1.27, 1.30, 1.33, 1.37, 1.40, 1.43,
+	1.47, 1.50, 1.54, 1.58, 1.62, 1.65, 1.69, 1.74, 1.78, 1.82, 1.87, 1.91, 1.96, 2.00, 2.05, 2.10,
+	2.15, 2.21, 2.26, 2.32, 2.37, 2.43, 2.49, 2.55, 2.61, 2.67, 2.74, 2.80, 2.87, 2.94, 3.01, 3.09,
+	3.16, 3.24, 3.32, 3.40, 3.48, 3.57, 3.65, 3.74, 3.83, 3.92, 4.02, 4.12, 4.22, 4.32, 4.42, 4.53,
+	4.64, 4.75, 4.87, 4.99, 5.11, 5.23, 5.36, 5.49, 5.62, 5.76, 5.90, 6.04, 6.19, 6.34, 6.49, 6.65,
+	6.81, 6.98, 7.15, 7.32, 7.50, 7.68, 7.87, 8.06, 8.25, 8.45, 8.66, 8.87, 9.09, 9.31, 9.53, 9.76,
+}
+
+// DividerCandidate describes one series/parallel resistor choice over the temperature limits.
+type DividerCandidate struct {
+	RS             float64 // Ω
+	RP             float64 // Ω, 0 = none
+	MinSensitivity float64 // LSB/°C at the least sensitive point
+	WorstStep      float64 // °C/LSB at the least sensitive point
+	Quantisation   float64 // K, worst case quantisation error of the firmware conversion
+	CodeSpan       float64 // ADC codes between the temperature limits
+}
+
+// ESeriesValues returns the preferred resistor values (Ω) of series between min and max. With
+// ESeriesNone it returns a log spaced grid of the same density as E96.
+func ESeriesValues(series string, min, max float64) ([]float64, error) {
+	var mantissas []float64
+
+	switch series {
+	case ESeriesE24:
+		mantissas = e24
+	case ESeriesE96:
+		mantissas = e96
+	case ESeriesNone, "":
+		mantissas = make([]float64, optimisePerDecade)
+		for i := range mantissas {
+			mantissas[i] = math.Pow(10, float64(i)/float64(optimisePerDecade))
+		}
+	default:
+		return nil, fmt.Errorf("unknown E series %q", series)
+	}
+
+	var values []float64
+	for decade := math.Pow(10, math.Floor(math.Log10(min))); decade <= max; decade *= 10 {
+		for _, m := range mantissas {
+			// Round away the error of the decade multiplication.
+			v := math.Round(m*decade*100) / 100
+			if v >= min && v <= max {
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// OptimiseDivider searches series resistors, and with withParallel parallel resistors, of the
+// given E series for the divider that reads the model best between the temperature limits of cfg.
+// Candidates are ranked by the largest minimum sensitivity, or by the smallest worst case
+// quantisation error of the firmware conversion among the most sensitive ones. The best top
+// candidates are returned.
+func OptimiseDivider(cfg models.Config, model Model, metric, series string, withParallel bool, top int) ([]DividerCandidate, error) {
+	if metric != MetricSensitivity && metric != MetricQuantisation {
+		return nil, fmt.Errorf("unknown optimisation metric %q", metric)
+	}
+
+	values, err := ESeriesValues(series, optimiseMinResistance, optimiseMaxResistance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resistance and its slope over the range do not depend on the divider, so sample them once.
+	temps, resistances, err := sampleResistances(cfg, model, optimiseGridPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	slopes := make([]float64, optimiseGridPoints)
+	for i := range slopes {
+		lo, hi := max(i-1, 0), min(i+1, optimiseGridPoints-1)
+		slopes[i] = (resistances[hi] - resistances[lo]) / (temps[hi] - temps[lo])
+	}
+
+	// ADC codes per unit divider ratio.
+	codes := float64(uint(1)<<cfg.ADCResolution) * supplyRatio(cfg)
+
+	parallels := []float64{0}
+	if withParallel {
+		parallels = append(parallels, values...)
+	}
+
+	var candidates []DividerCandidate
+	for _, rp := range parallels {
+		for _, rs := range values {
+			c := DividerCandidate{RS: rs, RP: rp, MinSensitivity: math.Inf(1)}
+
+			for i, r := range resistances {
+				rNet, dNet := r, 1.0
+				if rp != 0 {
+					rNet = r * rp / (r + rp)
+					dNet = (rp / (r + rp)) * (rp / (r + rp))
+				}
+
+				// The slope of the ratio has the same magnitude on either side of the divider.
+				sensitivity := math.Abs(codes * rs / ((rs + rNet) * (rs + rNet)) * dNet * slopes[i])
+				c.MinSensitivity = math.Min(c.MinSensitivity, sensitivity)
+			}
+
+			if c.MinSensitivity == 0 {
+				continue
+			}
+			c.WorstStep = 1 / c.MinSensitivity
+			c.CodeSpan = codes * math.Abs(dividerRatio(cfg.Topology, resistances[len(resistances)-1], rs, rp)-dividerRatio(cfg.Topology, resistances[0], rs, rp))
+			candidates = append(candidates, c)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no divider reads the model between %.1f and %.1f °C", cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].MinSensitivity > candidates[b].MinSensitivity
+	})
+
+	// The quantisation error is evaluated code by code, so only the most sensitive
+	// candidates are shortlisted for it.
+	shortlist := max(top*optimiseShortlist, optimiseShortlist)
+	if len(candidates) > shortlist {
+		candidates = candidates[:shortlist]
+	}
+	for i := range candidates {
+		c := &candidates[i]
+		c.Quantisation = quantisationError(cfg, model, c.RS, c.RP, codes*dividerRatio(cfg.Topology, resistances[0], c.RS, c.RP), codes*dividerRatio(cfg.Topology, resistances[len(resistances)-1], c.RS, c.RP))
+	}
+
+	if metric == MetricQuantisation {
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].Quantisation < candidates[b].Quantisation
+		})
+	}
+
+	if top > 0 && len(candidates) > top {
+		candidates = candidates[:top]
+	}
+	return candidates, nil
+}
+
+// quantisationError returns the worst case temperature error (K) from quantising readings
+// between codeA and codeB. Through the model a reading is off by up to half an ADC code; a LUT
+// returns the temperature at the start of its step, so a reading is off by up to a whole step.
+func quantisationError(cfg models.Config, model Model, rs, rp, codeA, codeB float64) float64 {
+	codes := float64(uint(1) << cfg.ADCResolution)
+	step, share := 1.0, 0.5
+	if cfg.LUTSize != 0 {
+		step, share = codes/float64(cfg.LUTSize), 1.0
+	}
+
+	temp := func(code float64) float64 {
+		return model(dividerResistance(cfg.Topology, code/(codes*supplyRatio(cfg)), rs, rp)) - KelvinToCelsius
+	}
+
+	var worst float64
+	lo, hi := math.Min(codeA, codeB), math.Max(codeA, codeB)
+	for b := math.Floor(lo/step) * step; b < hi; b += step {
+		t0, t1 := temp(math.Max(b, 0.5)), temp(math.Min(b+step, codes-0.5))
+		if math.IsNaN(t0) || math.IsNaN(t1) {
+			continue
+		}
+		worst = math.Max(worst, share*math.Abs(t1-t0))
+	}
+	return worst
+}
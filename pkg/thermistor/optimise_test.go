@@ -0,0 +1,74 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestESeriesValues(t *testing.T) {
+	values, err := ESeriesValues(ESeriesE24, 1000, 10000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 25 || values[0] != 1000 || values[len(values)-1] != 10000 {
+		t.Fatalf("e24 1k..10k = %v; want 1k to 10k in 25 values", values)
+	}
+	found := false
+	for _, v := range values {
+		found = found || v == 4700
+	}
+	if !found {
+		t.Errorf("e24 values %v do not contain 4.7k", values)
+	}
+
+	if _, err := ESeriesValues("e12", 1000, 10000); err == nil {
+		t.Errorf("expected an error for an unknown series")
+	}
+}
+
+func TestOptimiseDivider(t *testing.T) {
+	cfg := models.Config{ADCResolution: 12, LowerLimitTemp: 20, UpperLimitTemp: 30}
+	model := BetaModel(testBeta)
+
+	candidates, err := OptimiseDivider(cfg, model, MetricSensitivity, ESeriesE96, false, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("got %d candidates; want 3", len(candidates))
+	}
+
+	// The NTC is least sensitive at the hot end of the window, so the best RS lies between
+	// the resistances at the limits, nearer the hot one.
+	rHot, _ := ResistanceFromTemperature(model, 30+KelvinToCelsius)
+	rCold, _ := ResistanceFromTemperature(model, 20+KelvinToCelsius)
+	if candidates[0].RS < rHot || candidates[0].RS > (rHot+rCold)/2 {
+		t.Errorf("best RS = %.0f Ω; want between %.0f and %.0f Ω", candidates[0].RS, rHot, (rHot+rCold)/2)
+	}
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].MinSensitivity > candidates[i-1].MinSensitivity {
+			t.Errorf("candidates not ranked by sensitivity: %v", candidates)
+		}
+	}
+
+	// Half an LSB through the model at the least sensitive point.
+	best := candidates[0]
+	if !floatAlmostEqualPercentage(best.Quantisation, 0.5*best.WorstStep, 0.02) {
+		t.Errorf("quantisation = %g K; want about %g K", best.Quantisation, 0.5*best.WorstStep)
+	}
+
+	// A LUT holds a whole step of codes per entry.
+	cfg.LUTSize = 256
+	lutCandidates, err := OptimiseDivider(cfg, model, MetricQuantisation, ESeriesE96, false, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lutCandidates[0].Quantisation <= 10*best.Quantisation {
+		t.Errorf("LUT quantisation %g K should far exceed the model's %g K", lutCandidates[0].Quantisation, best.Quantisation)
+	}
+
+	if _, err := OptimiseDivider(cfg, model, "accuracy", ESeriesE96, false, 3); err == nil {
+		t.Errorf("expected an error for an unknown metric")
+	}
+}
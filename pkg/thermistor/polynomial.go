@@ -0,0 +1,96 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// Powers of ln(R) used by the classic three-term Steinhart-Hart equation.
+var SteinhartPowers = []int{0, 1, 3}
+
+// PolynomialPowers returns the full basis 1, ln(R), ..., ln(R)^order.
+func PolynomialPowers(order int) []int {
+	powers := make([]int, order+1)
+	for i := range powers {
+		powers[i] = i
+	}
+	return powers
+}
+
+// PolynomialCalculation evaluates 1/T = Σ coeff[k]*ln(R)^k and returns the temperature (K).
+func PolynomialCalculation(resistance float64, coeff []float64) float64 {
+	lnR := math.Log(resistance)
+
+	var sum float64
+	for k := len(coeff) - 1; k >= 0; k-- {
+		sum = sum*lnR + coeff[k]
+	}
+	return 1 / sum
+}
+
+func PolynomialModel(coeff []float64) Model {
+	return func(resistance float64) float64 {
+		return PolynomialCalculation(resistance, coeff)
+	}
+}
+
+// SteinhartToPolynomial expands {a, b, c} into the dense form {a, b, 0, c}.
+func SteinhartToPolynomial(coeff [3]float64) []float64 {
+	return []float64{coeff[0], coeff[1], 0, coeff[2]}
+}
+
+// FindLnPolynomialCoefficients fits 1/T against the given powers of ln(R), honouring the
+// point weights. The result is dense, indexed by power, with zeros for powers not in the basis.
+func FindLnPolynomialCoefficients(points []models.ThermistorPoint, powers []int) ([]float64, error) {
+	if len(points) < len(powers) {
+		return nil, fmt.Errorf("not enough points (%d) to fit %d coefficients", len(points), len(powers))
+	}
+
+	maxPower := 0
+	for _, p := range powers {
+		if p > maxPower {
+			maxPower = p
+		}
+	}
+
+	X := make([][]float64, len(points))
+	Y := make([]float64, len(points))
+
+	for i, p := range points {
+		X[i] = lnPolynomialRow(p.Resistance, powers)
+		Y[i] = 1.0 / (p.Temp + KelvinToCelsius)
+	}
+
+	coeffs, err := weightedLeastSquares(X, Y, pointWeights(points))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, maxPower+1)
+	for i, p := range powers {
+		result[p] = coeffs[i]
+	}
+	return result, nil
+}
+
+func FindPolynomialCoefficients(points []models.ThermistorPoint, order int) ([]float64, error) {
+	if order < 1 {
+		return nil, fmt.Errorf("polynomial order must be at least 1, got %d", order)
+	}
+	return FindLnPolynomialCoefficients(points, PolynomialPowers(order))
+}
+
+func CheckPolynomialDeviation(points []models.ThermistorPoint, coeff []float64) ([]models.DeviationTable, float64, float64) {
+	return CheckModelDeviation(points, PolynomialModel(coeff))
+}
+
+func lnPolynomialRow(resistance float64, powers []int) []float64 {
+	lnR := math.Log(resistance)
+	row := make([]float64, len(powers))
+	for i, p := range powers {
+		row[i] = math.Pow(lnR, float64(p))
+	}
+	return row
+}
@@ -0,0 +1,89 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+var testPolyCoeff = []float64{9.878477e-4, 2.121908e-4, 4.972205e-6, -1.174091e-8}
+
+func polyTestPoints() []models.ThermistorPoint {
+	var points []models.ThermistorPoint
+
+	for r := 500.0; r < 200000; r *= 1.5 {
+		temp := PolynomialCalculation(r, testPolyCoeff) - KelvinToCelsius
+		points = append(points, models.ThermistorPoint{Temp: temp, Resistance: r})
+	}
+	return points
+}
+
+func TestPolynomialCalculation(t *testing.T) {
+	dense := SteinhartToPolynomial(testSteinhartCoeff)
+
+	for _, row := range testPoints {
+		want := SteinhartCalculation(row.Resistance, testSteinhartCoeff)
+		result := PolynomialCalculation(row.Resistance, dense)
+		if !floatAlmostEqual(result, want, 1e-9) {
+			t.Errorf("result = %f; want %f", result, want)
+		}
+	}
+}
+
+func TestFindSteinhartCoefficients_MatchesPolynomialBasis(t *testing.T) {
+	dense, err := FindLnPolynomialCoefficients(testPoints, SteinhartPowers)
+	if err != nil {
+		t.Fatalf("FindLnPolynomialCoefficients returned error: %v", err)
+	}
+
+	if len(dense) != 4 || dense[2] != 0 {
+		t.Fatalf("expected dense {a, b, 0, c}, got %v", dense)
+	}
+
+	coeff, err := FindSteinhartCoefficients(testPoints)
+	if err != nil {
+		t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+	}
+
+	if coeff[0] != dense[0] || coeff[1] != dense[1] || coeff[2] != dense[3] {
+		t.Errorf("coefficients %v do not match dense %v", coeff, dense)
+	}
+}
+
+func TestFindPolynomialCoefficients(t *testing.T) {
+	points := polyTestPoints()
+
+	result, err := FindPolynomialCoefficients(points, 3)
+	if err != nil {
+		t.Fatalf("FindPolynomialCoefficients returned error: %v", err)
+	}
+
+	if len(result) != len(testPolyCoeff) {
+		t.Fatalf("got %d coefficients, want %d", len(result), len(testPolyCoeff))
+	}
+
+	_, maxDev, _ := CheckPolynomialDeviation(points, result)
+	if maxDev > 1e-6 {
+		t.Errorf("max deviation = %.3g, want 0", maxDev)
+	}
+
+	// The three-term equation cannot represent the ln²R term exactly.
+	steinhart, err := FindSteinhartCoefficients(points)
+	if err != nil {
+		t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+	}
+	_, steinhartMaxDev, _ := CheckDeviation(points, steinhart)
+	if steinhartMaxDev <= maxDev {
+		t.Errorf("steinhart max deviation %.3g not worse than polynomial %.3g", steinhartMaxDev, maxDev)
+	}
+}
+
+func TestFindPolynomialCoefficients_Errors(t *testing.T) {
+	if _, err := FindPolynomialCoefficients(testPoints, 0); err == nil {
+		t.Errorf("expected error for order 0")
+	}
+
+	if _, err := FindPolynomialCoefficients(testPoints, 3); err == nil {
+		t.Errorf("expected error for fewer points than coefficients")
+	}
+}
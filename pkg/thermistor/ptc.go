@@ -0,0 +1,122 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// Default order of the PTC polynomial, the quadratic of the KTY datasheets.
+const PTCDefaultOrder int = 2
+
+const ptcIterations int = 20
+
+// PTCResistance returns the resistance (Ω) at tempC for the dense polynomial coeff in
+// powers of (T - models.PTCReferenceTemp), the form used in KTY81 datasheets.
+func PTCResistance(tempC float64, coeff []float64) float64 {
+	x := tempC - models.PTCReferenceTemp
+
+	var r float64
+	for k := len(coeff) - 1; k >= 0; k-- {
+		r = r*x + coeff[k]
+	}
+	return r
+}
+
+// PTCCalculation returns the temperature (K) at resistance. The linear and quadratic cases
+// are solved directly on the rising branch; higher orders refine the quadratic root with
+// Newton's method.
+func PTCCalculation(resistance float64, coeff []float64) float64 {
+	x := ptcQuadraticRoot(resistance, coeff)
+
+	if len(coeff) > 3 {
+		for i := 0; i < ptcIterations; i++ {
+			var f, df float64
+			for k := len(coeff) - 1; k >= 0; k-- {
+				df = df*x + f
+				f = f*x + coeff[k]
+			}
+			if df <= 0 {
+				break
+			}
+			step := (f - resistance) / df
+			x -= step
+			if math.Abs(step) < 1e-9 {
+				break
+			}
+		}
+	}
+
+	return x + models.PTCReferenceTemp + KelvinToCelsius
+}
+
+// ptcQuadraticRoot solves the first three terms of coeff for x = T - models.PTCReferenceTemp,
+// using 2(R - c0) / (c1 + √disc), the conjugate of (-c1 + √disc) / 2c2. It keeps its precision
+// for a small c2 and reduces to the linear root when c2 is 0.
+func ptcQuadraticRoot(resistance float64, coeff []float64) float64 {
+	c0, c1 := coeff[0], coeff[1]
+	var c2 float64
+	if len(coeff) >= 3 {
+		c2 = coeff[2]
+	}
+
+	disc := c1*c1 + 4*c2*(resistance-c0)
+	if disc < 0 {
+		// Beyond the vertex of the quadratic, outside the range of the sensor.
+		disc = 0
+	}
+	return 2 * (resistance - c0) / (c1 + math.Sqrt(disc))
+}
+
+func PTCModel(coeff []float64) Model {
+	return func(resistance float64) float64 {
+		return PTCCalculation(resistance, coeff)
+	}
+}
+
+// FindPTCCoefficients fits R = Σ coeff[k]*(T - models.PTCReferenceTemp)^k by least squares in
+// resistance, honouring the point weights.
+func FindPTCCoefficients(points []models.ThermistorPoint, order int) ([]float64, error) {
+	if order < 1 {
+		return nil, fmt.Errorf("PTC polynomial order must be at least 1, got %d", order)
+	}
+	if len(points) < order+1 {
+		return nil, fmt.Errorf("not enough points (%d) to fit %d coefficients", len(points), order+1)
+	}
+
+	X := make([][]float64, len(points))
+	Y := make([]float64, len(points))
+
+	// Columns in units of 100 K keep the design matrix well conditioned.
+	for i, p := range points {
+		x := (p.Temp - models.PTCReferenceTemp) / tempColumnScale
+		X[i] = make([]float64, order+1)
+		X[i][0] = 1
+		for k := 1; k <= order; k++ {
+			X[i][k] = X[i][k-1] * x
+		}
+		Y[i] = p.Resistance
+	}
+
+	coeffs, err := weightedLeastSquares(X, Y, resistanceWeights(points))
+	if err != nil {
+		return nil, err
+	}
+
+	scale := 1.0
+	for k := range coeffs {
+		coeffs[k] /= scale
+		scale *= tempColumnScale
+	}
+
+	if coeffs[1] <= 0 {
+		return nil, fmt.Errorf("PTC fit produced a non-positive slope (%.3g Ω/K); data is not PTC", coeffs[1])
+	}
+
+	return coeffs, nil
+}
+
+func CheckPTCDeviation(points []models.ThermistorPoint, coeff []float64) ([]models.DeviationTable, float64, float64) {
+	return CheckModelDeviation(points, PTCModel(coeff))
+}
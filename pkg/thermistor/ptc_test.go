@@ -0,0 +1,82 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// KTY81/210 datasheet: R25 = 2000 Ω, A = 7.88e-3, B = 1.937e-5
+var testPTCCoeff = []float64{2000, 2000 * 7.88e-3, 2000 * 1.937e-5}
+
+func ptcTestPoints(coeff []float64) []models.ThermistorPoint {
+	var points []models.ThermistorPoint
+	for temp := -55.0; temp <= 150; temp += 5 {
+		points = append(points, models.ThermistorPoint{Temp: temp, Resistance: PTCResistance(temp, coeff)})
+	}
+	return points
+}
+
+func TestPTCCalculation(t *testing.T) {
+	quartic := []float64{2000, 15.7, 0.04, -1e-5, 2e-8}
+	// A zero or tiny c2 would divide by zero or cancel in (-c1 + √disc) / 2c2.
+	linear := []float64{2000, 15.76, 0}
+	nearLinear := []float64{2000, 15.76, 1e-12}
+
+	for _, coeff := range [][]float64{testPTCCoeff, testPTCCoeff[:2], quartic, linear, nearLinear} {
+		for _, p := range ptcTestPoints(coeff) {
+			result := PTCCalculation(p.Resistance, coeff) - KelvinToCelsius
+			if !floatAlmostEqual(result, p.Temp, 1e-6) {
+				t.Errorf("PTCCalculation(%f, %v) = %f; want %f", p.Resistance, coeff, result, p.Temp)
+			}
+		}
+	}
+}
+
+func TestFindPTCCoefficients(t *testing.T) {
+	result, err := FindPTCCoefficients(ptcTestPoints(testPTCCoeff), PTCDefaultOrder)
+	if err != nil {
+		t.Fatalf("FindPTCCoefficients returned error: %v", err)
+	}
+
+	for i := range testPTCCoeff {
+		if !floatAlmostEqualPercentage(result[i], testPTCCoeff[i], 1e-6) {
+			t.Errorf("result[%d] = %g; want %g", i, result[i], testPTCCoeff[i])
+		}
+	}
+
+	if _, err := FindPTCCoefficients(betaTestPoints(), PTCDefaultOrder); err == nil {
+		t.Errorf("expected error fitting an NTC table")
+	}
+
+	if _, err := FindPTCCoefficients(testPoints, 3); err == nil {
+		t.Errorf("expected error with fewer points than coefficients")
+	}
+}
+
+func TestGenerateModelLUT_KTY(t *testing.T) {
+	cfg := models.Config{
+		LUTSize:        128,
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             2.7,
+		UpperLimitTemp: 150,
+		LowerLimitTemp: -55,
+		Sensor:         models.SensorPTC,
+	}
+
+	temps, _, _, err := GenerateModelLUT(cfg, PTCModel(testPTCCoeff))
+	if err != nil {
+		t.Fatalf("GenerateModelLUT returned error: %v", err)
+	}
+
+	if temps[0] != cfg.LowerLimitTemp || temps[cfg.LUTSize-1] != cfg.UpperLimitTemp {
+		t.Errorf("endpoints = %f, %f; want %f, %f", temps[0], temps[cfg.LUTSize-1], cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	}
+
+	for i := 1; i < len(temps); i++ {
+		if temps[i] < temps[i-1] {
+			t.Errorf("LUT not rising at index %d: %f < %f", i, temps[i], temps[i-1])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+const (
+	RobustHuber = "huber"
+	RobustTukey = "tukey"
+)
+
+// Standard tuning constants giving 95% efficiency on normally distributed residuals.
+const huberK float64 = 1.345
+const tukeyC float64 = 4.685
+
+// Residuals beyond this many robust standard deviations are reported as outliers.
+const OutlierThreshold float64 = 3.0
+
+// Fully rejected points keep a tiny weight so they stay distinct from unweighted (0) points.
+const robustMinWeight float64 = 1e-9
+
+const robustMaxIterations int = 50
+const robustTolerance float64 = 1e-6
+
+// Outlier is a point whose temperature residual is far outside the robust spread of the rest.
+type Outlier struct {
+	Index    int
+	Point    models.ThermistorPoint
+	Residual float64 // K
+	Sigmas   float64
+}
+
+// RobustWeights runs iteratively reweighted least squares with the Huber or Tukey bisquare
+// function over the given powers of ln(R). It returns a copy of points whose weights
+// include the robust weights, so any later fit is outlier resistant, and the suspected outliers.
+func RobustWeights(points []models.ThermistorPoint, powers []int, method string) ([]models.ThermistorPoint, []Outlier, error) {
+	var weightFunc func(u float64) float64
+
+	switch method {
+	case RobustHuber:
+		weightFunc = func(u float64) float64 {
+			if math.Abs(u) <= huberK {
+				return 1
+			}
+			return huberK / math.Abs(u)
+		}
+	case RobustTukey:
+		weightFunc = func(u float64) float64 {
+			if math.Abs(u) >= tukeyC {
+				return 0
+			}
+			v := 1 - (u/tukeyC)*(u/tukeyC)
+			return v * v
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown robust method %q, use huber or tukey", method)
+	}
+
+	if len(points) <= len(powers) {
+		return nil, nil, fmt.Errorf("robust fit needs more points (%d) than coefficients (%d)", len(points), len(powers))
+	}
+
+	base := pointWeights(points)
+	robust := make([]float64, len(points))
+	for i := range robust {
+		robust[i] = 1
+	}
+
+	weighted := make([]models.ThermistorPoint, len(points))
+	copy(weighted, points)
+
+	residuals := make([]float64, len(points))
+	var scale float64
+
+	for iter := 0; iter < robustMaxIterations; iter++ {
+		for i := range weighted {
+			weighted[i].Weight = lmWeight(base, i) * math.Max(robust[i], robustMinWeight)
+		}
+
+		coeff, err := FindLnPolynomialCoefficients(weighted, powers)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i, p := range points {
+			residuals[i] = PolynomialCalculation(p.Resistance, coeff) - KelvinToCelsius - p.Temp
+		}
+
+		scale = madScale(residuals)
+		if scale == 0 {
+			break
+		}
+
+		var change float64
+		for i, r := range residuals {
+			w := weightFunc(r / scale)
+			change = math.Max(change, math.Abs(w-robust[i]))
+			robust[i] = w
+		}
+		if change < robustTolerance {
+			break
+		}
+	}
+
+	for i := range weighted {
+		weighted[i].Weight = lmWeight(base, i) * math.Max(robust[i], robustMinWeight)
+	}
+
+	var outliers []Outlier
+	if scale > 0 {
+		for i, r := range residuals {
+			if sigmas := math.Abs(r) / scale; sigmas > OutlierThreshold {
+				outliers = append(outliers, Outlier{Index: i, Point: points[i], Residual: r, Sigmas: sigmas})
+			}
+		}
+	}
+
+	return weighted, outliers, nil
+}
+
+// ExcludeOutliers returns the points without the outliers.
+func ExcludeOutliers(points []models.ThermistorPoint, outliers []Outlier) []models.ThermistorPoint {
+	excluded := make(map[int]bool)
+	for _, o := range outliers {
+		excluded[o.Index] = true
+	}
+
+	var kept []models.ThermistorPoint
+	for i, p := range points {
+		if !excluded[i] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// madScale is the normalised median absolute deviation, a robust estimate of the standard deviation.
+func madScale(residuals []float64) float64 {
+	med := median(residuals)
+
+	dev := make([]float64, len(residuals))
+	for i, r := range residuals {
+		dev[i] = math.Abs(r - med)
+	}
+	return 1.4826 * median(dev)
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,76 @@
+package thermistor
+
+import (
+	"testing"
+)
+
+func TestRobustWeights(t *testing.T) {
+	points := betaTestPoints()
+	bad := 13
+	points[bad].Resistance *= 1.1
+
+	for _, method := range []string{RobustHuber, RobustTukey} {
+		t.Run(method, func(t *testing.T) {
+			weighted, outliers, err := RobustWeights(points, SteinhartPowers, method)
+			if err != nil {
+				t.Fatalf("RobustWeights returned error: %v", err)
+			}
+
+			if len(outliers) != 1 || outliers[0].Index != bad {
+				t.Fatalf("expected outlier at index %d, got %+v", bad, outliers)
+			}
+
+			if weighted[bad].Weight >= weighted[0].Weight {
+				t.Errorf("outlier weight %g not below clean weight %g", weighted[bad].Weight, weighted[0].Weight)
+			}
+
+			plain, err := FindSteinhartCoefficients(points)
+			if err != nil {
+				t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+			}
+			robust, err := FindSteinhartCoefficients(weighted)
+			if err != nil {
+				t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+			}
+
+			clean := ExcludeOutliers(points, outliers)
+			if len(clean) != len(points)-1 {
+				t.Fatalf("ExcludeOutliers kept %d points, want %d", len(clean), len(points)-1)
+			}
+
+			_, plainDev, _ := CheckDeviation(clean, plain)
+			_, robustDev, _ := CheckDeviation(clean, robust)
+			if robustDev >= plainDev {
+				t.Errorf("robust fit max deviation on clean points %.3g not better than plain %.3g", robustDev, plainDev)
+			}
+		})
+	}
+}
+
+func TestRobustWeights_Clean(t *testing.T) {
+	_, outliers, err := RobustWeights(betaTestPoints(), BetaPowers, RobustTukey)
+	if err != nil {
+		t.Fatalf("RobustWeights returned error: %v", err)
+	}
+	if len(outliers) != 0 {
+		t.Errorf("unexpected outliers in exact data: %+v", outliers)
+	}
+}
+
+func TestRobustWeights_Errors(t *testing.T) {
+	if _, _, err := RobustWeights(betaTestPoints(), SteinhartPowers, "bogus"); err == nil {
+		t.Errorf("expected error for unknown method")
+	}
+	if _, _, err := RobustWeights(testPoints, SteinhartPowers, RobustHuber); err == nil {
+		t.Errorf("expected error for too few points")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if m := median([]float64{3, 1, 2}); m != 2 {
+		t.Errorf("median = %g, want 2", m)
+	}
+	if m := median([]float64{4, 1, 2, 3}); m != 2.5 {
+		t.Errorf("median = %g, want 2.5", m)
+	}
+}
@@ -0,0 +1,141 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// AutoBandEdges splits the points into n bands holding roughly equal numbers of points and
+// returns the n-1 inner edge temperatures. Edges fall on table points so both bands share it.
+func AutoBandEdges(points []models.ThermistorPoint, n int) []float64 {
+	sorted := sortedByTemp(points)
+
+	var edges []float64
+	for i := 1; i < n; i++ {
+		edges = append(edges, sorted[i*(len(sorted)-1)/n].Temp)
+	}
+	return edges
+}
+
+// FindSegmentedCoefficients fits the given powers of ln(R) separately in each band between
+// the edge temperatures (°C). Every band after the first is constrained to meet the previous
+// band exactly at the shared edge, so the piecewise curve is continuous.
+func FindSegmentedCoefficients(points []models.ThermistorPoint, powers []int, edges []float64) ([]models.Segment, error) {
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("no band edges given")
+	}
+	if !sort.Float64sAreSorted(edges) {
+		return nil, fmt.Errorf("band edges must be in ascending order: %v", edges)
+	}
+	if powers[0] != 0 {
+		return nil, fmt.Errorf("segmented fit needs a constant term in the basis")
+	}
+
+	sorted := sortedByTemp(points)
+	bounds := append(append([]float64{math.Inf(-1)}, edges...), math.Inf(1))
+
+	var segments []models.Segment
+	for b := 0; b+1 < len(bounds); b++ {
+		lower, upper := bounds[b], bounds[b+1]
+		band := PointsInRange(sorted, lower, upper)
+
+		seg := models.Segment{Lower: lower, Upper: upper}
+		if !math.IsInf(upper, 1) {
+			r, err := interpolateResistance(sorted, upper)
+			if err != nil {
+				return nil, err
+			}
+			seg.Threshold = r
+		}
+
+		var err error
+		if b == 0 {
+			if len(band) < len(powers) {
+				return nil, fmt.Errorf("band below %.1f °C has %d points, need %d", upper, len(band), len(powers))
+			}
+			seg.Coeff, err = FindLnPolynomialCoefficients(band, powers)
+		} else {
+			if len(band) < len(powers)-1 {
+				return nil, fmt.Errorf("band %.1f..%.1f °C has %d points, need %d", lower, upper, len(band), len(powers)-1)
+			}
+			prev := segments[b-1]
+			seg.Coeff, err = findConstrainedCoefficients(band, powers, prev.Threshold, 1/PolynomialCalculation(prev.Threshold, prev.Coeff))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, seg)
+	}
+
+	return segments, nil
+}
+
+func SegmentedModel(segments []models.Segment) Model {
+	return func(resistance float64) float64 {
+		for _, seg := range segments {
+			if resistance >= seg.Threshold {
+				return PolynomialCalculation(resistance, seg.Coeff)
+			}
+		}
+		return PolynomialCalculation(resistance, segments[len(segments)-1].Coeff)
+	}
+}
+
+// findConstrainedCoefficients fits 1/T subject to passing through (rEdge, yEdge) by
+// eliminating the constant term: y - yEdge = Σ c_p (lnR^p - lnR_edge^p).
+func findConstrainedCoefficients(points []models.ThermistorPoint, powers []int, rEdge, yEdge float64) ([]float64, error) {
+	edgeRow := lnPolynomialRow(rEdge, powers)
+
+	X := make([][]float64, len(points))
+	Y := make([]float64, len(points))
+	for i, p := range points {
+		row := lnPolynomialRow(p.Resistance, powers)
+		X[i] = make([]float64, len(powers)-1)
+		for k := 1; k < len(powers); k++ {
+			X[i][k-1] = row[k] - edgeRow[k]
+		}
+		Y[i] = 1.0/(p.Temp+KelvinToCelsius) - yEdge
+	}
+
+	coeffs, err := weightedLeastSquares(X, Y, pointWeights(points))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, powers[len(powers)-1]+1)
+	result[0] = yEdge
+	for k := 1; k < len(powers); k++ {
+		result[powers[k]] = coeffs[k-1]
+		result[0] -= coeffs[k-1] * edgeRow[k]
+	}
+	return result, nil
+}
+
+// interpolateResistance finds the resistance at temp by interpolating ln(R) linearly in 1/T
+// between the neighbouring table points.
+func interpolateResistance(sorted []models.ThermistorPoint, temp float64) (float64, error) {
+	for i := 1; i < len(sorted); i++ {
+		lo, hi := sorted[i-1], sorted[i]
+		if temp >= lo.Temp && temp <= hi.Temp {
+			if lo.Temp == hi.Temp {
+				return lo.Resistance, nil
+			}
+			y := 1 / (temp + KelvinToCelsius)
+			yLo, yHi := 1/(lo.Temp+KelvinToCelsius), 1/(hi.Temp+KelvinToCelsius)
+			frac := (y - yLo) / (yHi - yLo)
+			return math.Exp(math.Log(lo.Resistance) + frac*(math.Log(hi.Resistance)-math.Log(lo.Resistance))), nil
+		}
+	}
+	return 0, fmt.Errorf("band edge %.1f °C is outside the table range", temp)
+}
+
+func sortedByTemp(points []models.ThermistorPoint) []models.ThermistorPoint {
+	sorted := make([]models.ThermistorPoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Temp < sorted[j].Temp })
+	return sorted
+}
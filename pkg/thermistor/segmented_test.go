@@ -0,0 +1,98 @@
+package thermistor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestAutoBandEdges(t *testing.T) {
+	points := betaTestPoints() // -40..125 in 5 °C steps
+
+	edges := AutoBandEdges(points, 3)
+	if len(edges) != 2 {
+		t.Fatalf("got %d edges, want 2", len(edges))
+	}
+	if edges[0] >= edges[1] || edges[0] <= -40 || edges[1] >= 125 {
+		t.Errorf("unexpected edges %v", edges)
+	}
+}
+
+func TestFindSegmentedCoefficients(t *testing.T) {
+	points := polyTestPoints()
+	edges := []float64{0, 60}
+
+	segments, err := FindSegmentedCoefficients(points, SteinhartPowers, edges)
+	if err != nil {
+		t.Fatalf("FindSegmentedCoefficients returned error: %v", err)
+	}
+
+	if len(segments) != 3 {
+		t.Fatalf("got %d segments, want 3", len(segments))
+	}
+	if segments[2].Threshold != 0 {
+		t.Errorf("last segment threshold = %g, want 0", segments[2].Threshold)
+	}
+
+	// Continuity at each edge.
+	for i := 0; i < len(segments)-1; i++ {
+		r := segments[i].Threshold
+		below := PolynomialCalculation(r, segments[i].Coeff)
+		above := PolynomialCalculation(r, segments[i+1].Coeff)
+		if !floatAlmostEqual(below, above, 1e-9) {
+			t.Errorf("discontinuity at edge %d: %f != %f", i, below, above)
+		}
+		if !floatAlmostEqual(below-KelvinToCelsius, edges[i], 0.1) {
+			t.Errorf("edge %d temperature = %f, want %f", i, below-KelvinToCelsius, edges[i])
+		}
+	}
+
+	single, err := FindSteinhartCoefficients(points)
+	if err != nil {
+		t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+	}
+
+	_, singleMaxDev, _ := CheckDeviation(points, single)
+	_, segMaxDev, _ := CheckModelDeviation(points, SegmentedModel(segments))
+	if segMaxDev >= singleMaxDev {
+		t.Errorf("segmented max deviation %.3g not better than single fit %.3g", segMaxDev, singleMaxDev)
+	}
+}
+
+func TestSegmentedModel(t *testing.T) {
+	segments := []models.Segment{
+		{Lower: math.Inf(-1), Upper: 25, Threshold: 10000, Coeff: []float64{1}},
+		{Lower: 25, Upper: math.Inf(1), Coeff: []float64{0.5}},
+	}
+	model := SegmentedModel(segments)
+
+	if model(20000) != 1 || model(10000) != 1 {
+		t.Errorf("resistance above threshold should use first segment")
+	}
+	if model(5000) != 2 {
+		t.Errorf("resistance below threshold should use last segment")
+	}
+}
+
+func TestFindSegmentedCoefficients_Errors(t *testing.T) {
+	points := polyTestPoints()
+
+	tests := []struct {
+		name  string
+		edges []float64
+	}{
+		{"no edges", nil},
+		{"unsorted", []float64{60, 0}},
+		{"outside table", []float64{500}},
+		{"too few points", []float64{points[1].Temp + 0.1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FindSegmentedCoefficients(points, SteinhartPowers, tt.edges); err == nil {
+				t.Errorf("expected error for %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package thermistor
+
+import "github.com/Eriosies/thermistor-lut-gen/models"
+
+// SelfHeatingPower returns the power (W) dissipated in a thermistor of the given resistance (Ω)
+// by the circuit in cfg. The voltage across the thermistor is the same on either side of the
+// divider, or the drop of the excitation current across the network for a current source.
+func SelfHeatingPower(cfg models.Config, resistance float64) float64 {
+	if resistance <= 0 {
+		return 0
+	}
+
+	rSeries := cfg.RS * 1000
+	rParallel := cfg.RP * 1000
+
+	rNet := resistance
+	if rParallel != 0 {
+		rNet = resistance * rParallel / (resistance + rParallel)
+	}
+
+	v := models.SupplyVoltage(cfg) * rNet / (rSeries + rNet)
+	if cfg.Topology == models.TopologyCurrent {
+		v = cfg.Excitation * 1e-6 * rNet
+	}
+	return v * v / resistance
+}
+
+// SelfHeatingOffset returns the temperature rise (K) of the thermistor above ambient at the
+// given resistance, for cfg.Dissipation in mW/K. It is 0 if no dissipation constant is set.
+func SelfHeatingOffset(cfg models.Config, resistance float64) float64 {
+	if cfg.Dissipation <= 0 {
+		return 0
+	}
+	return SelfHeatingPower(cfg, resistance) * 1000 / cfg.Dissipation
+}
+
+// SelfHeatingModel corrects model for self-heating, returning the ambient temperature (K)
+// rather than that of the heated thermistor.
+func SelfHeatingModel(cfg models.Config, model Model) Model {
+	return func(resistance float64) float64 {
+		return model(resistance) - SelfHeatingOffset(cfg, resistance)
+	}
+}
+
+// WorstSelfHeating scans every ADC code whose temperature lies within the configured limits and
+// returns the largest self-heating offset (K), the temperature (°C) it occurs at and the power (W).
+func WorstSelfHeating(cfg models.Config, model Model) (float64, float64, float64) {
+	var worst, worstTemp, worstPower float64
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+
+	for adc := uint(1); adc < adcMax; adc++ {
+		resistance := resistanceFromADCValue(cfg, adc)
+		temp := model(resistance) - KelvinToCelsius
+		if temp < cfg.LowerLimitTemp || temp > cfg.UpperLimitTemp {
+			continue
+		}
+
+		if offset := SelfHeatingOffset(cfg, resistance); offset > worst {
+			worst, worstTemp, worstPower = offset, temp, SelfHeatingPower(cfg, resistance)
+		}
+	}
+	return worst, worstTemp, worstPower
+}
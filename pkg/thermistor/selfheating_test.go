@@ -0,0 +1,65 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestSelfHeatingPower(t *testing.T) {
+	cfg := models.Config{VoltageRef: 3.3, RS: 10, Dissipation: 2}
+
+	// Matched divider: half the supply across the thermistor.
+	power := SelfHeatingPower(cfg, 10000)
+	if !floatAlmostEqualPercentage(power, 1.65*1.65/10000, 1e-9) {
+		t.Errorf("power = %g; want %g", power, 1.65*1.65/10000)
+	}
+
+	offset := SelfHeatingOffset(cfg, 10000)
+	if !floatAlmostEqualPercentage(offset, power*1000/2, 1e-9) {
+		t.Errorf("offset = %g; want %g", offset, power*1000/2)
+	}
+
+	// The parallel resistor takes part of the current.
+	cfg.RP = 10
+	power = SelfHeatingPower(cfg, 10000)
+	if !floatAlmostEqualPercentage(power, 1.1*1.1/10000, 1e-9) {
+		t.Errorf("power with rp = %g; want %g", power, 1.1*1.1/10000)
+	}
+
+	if SelfHeatingPower(cfg, 0) != 0 {
+		t.Errorf("expected no power at 0 Ω")
+	}
+
+	cfg.Dissipation = 0
+	if SelfHeatingOffset(cfg, 10000) != 0 {
+		t.Errorf("expected no offset without a dissipation constant")
+	}
+}
+
+func TestSelfHeatingModel(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		Dissipation:    1,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+	}
+	model := SteinhartModel(testSteinhartCoeff)
+	corrected := SelfHeatingModel(cfg, model)
+
+	diff := model(10000) - corrected(10000)
+	if !floatAlmostEqualPercentage(diff, SelfHeatingOffset(cfg, 10000), 1e-9) {
+		t.Errorf("correction = %g; want %g", diff, SelfHeatingOffset(cfg, 10000))
+	}
+
+	// Power in the thermistor peaks when it matches the series resistor, at 25 °C.
+	worst, worstTemp, worstPower := WorstSelfHeating(cfg, model)
+	if !floatAlmostEqual(worstTemp, 25, 0.5) {
+		t.Errorf("worst case at %.2f °C; want 25 °C", worstTemp)
+	}
+	if !floatAlmostEqualPercentage(worstPower, 1.65*1.65/10000, 1e-3) || !floatAlmostEqualPercentage(worst, worstPower*1000, 1e-9) {
+		t.Errorf("worst = %g K at %g W", worst, worstPower)
+	}
+}
@@ -0,0 +1,120 @@
+package thermistor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Two sided confidence level of the reported temperature band.
+const ConfidenceLevel float64 = 0.95
+
+// FitStatistics reports the quality of dense coeff over the given powers of ln(R): RMS and R²
+// of the temperature residuals, the condition number of the design matrix, coefficient
+// standard errors and a confidence band at each table resistance. Standard errors and the
+// band need more points than coefficients and are left empty otherwise. When the covariance
+// cannot be computed they are also left empty and the other statistics are returned with the
+// error.
+func FitStatistics(points []models.ThermistorPoint, powers []int, coeff []float64) (models.FitStats, error) {
+	n, p := len(points), len(powers)
+	stats := models.FitStats{
+		Points:          n,
+		DOF:             n - p,
+		ConfidenceLevel: ConfidenceLevel,
+		Powers:          powers,
+	}
+
+	if n < p {
+		return stats, fmt.Errorf("not enough points (%d) for %d coefficients", n, p)
+	}
+
+	weights := pointWeights(points)
+	X := mat.NewDense(n, p, nil)
+	var ssRes, ssTot, meanTemp, ssResY float64
+
+	for _, pt := range points {
+		meanTemp += pt.Temp
+	}
+	meanTemp /= float64(n)
+
+	for i, pt := range points {
+		X.SetRow(i, lnPolynomialRow(pt.Resistance, powers))
+
+		calc := PolynomialCalculation(pt.Resistance, coeff)
+		dev := calc - KelvinToCelsius - pt.Temp
+		ssRes += dev * dev
+		ssTot += (pt.Temp - meanTemp) * (pt.Temp - meanTemp)
+
+		devY := 1/calc - 1/(pt.Temp+KelvinToCelsius)
+		ssResY += lmWeight(weights, i) * devY * devY
+	}
+
+	stats.RMS = math.Sqrt(ssRes / float64(n))
+	if ssTot > 0 {
+		stats.RSquared = 1 - ssRes/ssTot
+	}
+	stats.Condition = mat.Cond(X, 2)
+
+	for _, power := range powers {
+		stats.Coeff = append(stats.Coeff, coeff[power])
+	}
+
+	if stats.DOF <= 0 {
+		return stats, nil
+	}
+
+	// Cov = s² (XᵀWX)⁻¹ for the weighted linear problem in 1/T space, taken as s² R⁻¹R⁻ᵀ from
+	// the QR factorisation of √W·X. Forming XᵀWX would square the condition number of X.
+	Xw := mat.DenseCopyOf(X)
+	if weights != nil {
+		for i := 0; i < n; i++ {
+			for j := 0; j < p; j++ {
+				Xw.Set(i, j, X.At(i, j)*math.Sqrt(weights[i]))
+			}
+		}
+	}
+
+	var qr mat.QR
+	qr.Factorize(Xw)
+	var rFull mat.Dense
+	qr.RTo(&rFull)
+
+	r := mat.NewTriDense(p, mat.Upper, nil)
+	r.Copy(rFull.Slice(0, p, 0, p))
+	var rInv mat.TriDense
+	if err := rInv.InverseTri(r); err != nil {
+		return stats, fmt.Errorf("coefficient covariance is ill-conditioned, standard errors and confidence band omitted: %v", err)
+	}
+
+	var cov mat.Dense
+	cov.Mul(&rInv, rInv.T())
+	cov.Scale(ssResY/float64(stats.DOF), &cov)
+
+	for j := 0; j < p; j++ {
+		stats.StdErr = append(stats.StdErr, math.Sqrt(cov.At(j, j)))
+	}
+
+	tValue := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(stats.DOF)}.Quantile(1 - (1-ConfidenceLevel)/2)
+
+	sorted := make([]models.ThermistorPoint, n)
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Resistance < sorted[j].Resistance })
+
+	for _, pt := range sorted {
+		x := mat.NewVecDense(p, lnPolynomialRow(pt.Resistance, powers))
+		varY := mat.Inner(x, &cov, x)
+		tK := PolynomialCalculation(pt.Resistance, coeff)
+
+		stats.Band = append(stats.Band, models.ConfidencePoint{
+			Resistance:  pt.Resistance,
+			Temperature: tK - KelvinToCelsius,
+			HalfWidth:   tValue * math.Sqrt(varY) * tK * tK,
+		})
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,104 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestFitStatistics(t *testing.T) {
+	points := polyTestPoints()
+
+	coeff, err := FindLnPolynomialCoefficients(points, SteinhartPowers)
+	if err != nil {
+		t.Fatalf("FindLnPolynomialCoefficients returned error: %v", err)
+	}
+
+	stats, err := FitStatistics(points, SteinhartPowers, coeff)
+	if err != nil {
+		t.Fatalf("FitStatistics returned error: %v", err)
+	}
+
+	if stats.DOF != len(points)-3 {
+		t.Errorf("DOF = %d, want %d", stats.DOF, len(points)-3)
+	}
+
+	fullTable, _, _ := CheckPolynomialDeviation(points, coeff)
+	var sum float64
+	for _, row := range fullTable {
+		sum += row.Deviation * row.Deviation
+	}
+	if !floatAlmostEqualPercentage(stats.RMS*stats.RMS*float64(len(points)), sum, 1e-9) {
+		t.Errorf("RMS = %.3g does not match deviation table", stats.RMS)
+	}
+
+	if stats.RSquared <= 0.999 || stats.RSquared > 1 {
+		t.Errorf("R² = %f, want close to 1", stats.RSquared)
+	}
+	if stats.Condition <= 1 {
+		t.Errorf("condition number = %g, want > 1", stats.Condition)
+	}
+	if len(stats.StdErr) != 3 || len(stats.Coeff) != 3 {
+		t.Fatalf("expected 3 coefficients and standard errors, got %v %v", stats.Coeff, stats.StdErr)
+	}
+	for i, se := range stats.StdErr {
+		if se <= 0 {
+			t.Errorf("standard error %d = %g, want > 0", i, se)
+		}
+	}
+
+	if len(stats.Band) != len(points) {
+		t.Fatalf("band has %d points, want %d", len(stats.Band), len(points))
+	}
+	for i := 1; i < len(stats.Band); i++ {
+		if stats.Band[i].Resistance < stats.Band[i-1].Resistance {
+			t.Errorf("band not sorted by resistance at %d", i)
+		}
+		if stats.Band[i].HalfWidth <= 0 {
+			t.Errorf("band half width at %d = %g, want > 0", i, stats.Band[i].HalfWidth)
+		}
+	}
+}
+
+func TestFitStatistics_ExactFit(t *testing.T) {
+	stats, err := FitStatistics(testPoints, SteinhartPowers, SteinhartToPolynomial(testSteinhartCoeff))
+	if err != nil {
+		t.Fatalf("FitStatistics returned error: %v", err)
+	}
+
+	if stats.DOF != 0 {
+		t.Errorf("DOF = %d, want 0", stats.DOF)
+	}
+	if stats.StdErr != nil || stats.Band != nil {
+		t.Errorf("standard errors and band should be empty without degrees of freedom")
+	}
+
+	if _, err := FitStatistics([]models.ThermistorPoint{testPoints[0]}, SteinhartPowers, SteinhartToPolynomial(testSteinhartCoeff)); err == nil {
+		t.Errorf("expected error for fewer points than coefficients")
+	}
+}
+
+func TestFitStatistics_HighOrder(t *testing.T) {
+	// A fifth order fit over -40..125 °C, where XᵀX is too ill-conditioned to invert.
+	var points []models.ThermistorPoint
+	for temp := -40.0; temp <= 125; temp += 5 {
+		points = append(points, models.ThermistorPoint{Temp: temp, Resistance: BetaResistance(temp+KelvinToCelsius, testBeta)})
+	}
+	powers := PolynomialPowers(5)
+
+	coeff, err := FindLnPolynomialCoefficients(points, powers)
+	if err != nil {
+		t.Fatalf("FindLnPolynomialCoefficients returned error: %v", err)
+	}
+
+	stats, err := FitStatistics(points, powers, coeff)
+	if err != nil {
+		t.Fatalf("FitStatistics returned error: %v", err)
+	}
+	if stats.Condition < 1e8 {
+		t.Errorf("condition number = %g; want an ill-conditioned design matrix", stats.Condition)
+	}
+	if len(stats.StdErr) != len(powers) || len(stats.Band) != len(points) {
+		t.Errorf("got %d standard errors and %d band points; want %d and %d", len(stats.StdErr), len(stats.Band), len(powers), len(points))
+	}
+}
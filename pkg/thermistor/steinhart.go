@@ -1,6 +1,7 @@
 package thermistor
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/Eriosies/thermistor-lut-gen/models"
@@ -9,7 +10,16 @@ import (
 
 const KelvinToCelsius float64 = 273.15
 
+// Model converts a thermistor resistance (Ω) into an absolute temperature (K).
+type Model func(resistance float64) float64
+
 func leastSquares(X [][]float64, Y []float64) ([]float64, error) {
+	return weightedLeastSquares(X, Y, nil)
+}
+
+// weightedLeastSquares minimises Σ W[i]*(X[i]·beta - Y[i])² by scaling each row by sqrt(W[i]).
+// A nil W is an ordinary least squares solve.
+func weightedLeastSquares(X [][]float64, Y []float64, W []float64) ([]float64, error) {
 
 	rowsX := len(X)    //point count
 	colsX := len(X[0]) //coeff count
@@ -17,13 +27,24 @@ func leastSquares(X [][]float64, Y []float64) ([]float64, error) {
 	coeffs := make([]float64, colsX)
 
 	flatX := make([]float64, 0, rowsX*colsX)
-	for _, row := range X {
-		flatX = append(flatX, row...)
+	flatY := make([]float64, rowsX)
+	for i, row := range X {
+		scale := 1.0
+		if W != nil {
+			if W[i] < 0 {
+				return nil, fmt.Errorf("negative weight %g at row %d", W[i], i)
+			}
+			scale = math.Sqrt(W[i])
+		}
+		for _, x := range row {
+			flatX = append(flatX, x*scale)
+		}
+		flatY[i] = Y[i] * scale
 	}
 
 	Xmat := mat.NewDense(rowsX, colsX, flatX)
 
-	Yvec := mat.NewVecDense(len(Y), Y)
+	Yvec := mat.NewVecDense(rowsX, flatY)
 
 	var qr mat.QR
 	qr.Factorize(Xmat)
@@ -51,43 +72,39 @@ func SteinhartCalculation(resistance float64, coeff [3]float64) float64 {
 }
 
 func FindSteinhartCoefficients(points []models.ThermistorPoint) ([3]float64, error) {
-	n := len(points)
-	X := make([]float64, n*3)
-	Y := make([]float64, n)
-
-	for i, p := range points {
-		lnR := math.Log(p.Resistance)
-		X[i*3+0] = 1.0
-		X[i*3+1] = lnR
-		X[i*3+2] = lnR * lnR * lnR
-
-		Y[i] = 1.0 / (p.Temp + KelvinToCelsius)
-	}
+	var result [3]float64
 
-	X2D := make([][]float64, n)
-	for i := range X2D {
-		X2D[i] = X[i*3 : i*3+3]
+	coeffs, err := FindLnPolynomialCoefficients(points, SteinhartPowers)
+	if err != nil {
+		return result, err
 	}
 
-	coeffs, err := leastSquares(X2D, Y)
+	result[0], result[1], result[2] = coeffs[0], coeffs[1], coeffs[3]
+	return result, nil
+}
 
-	var result [3]float64
-	copy(result[:], coeffs)
-	return result, err
+func SteinhartModel(coeff [3]float64) Model {
+	return func(resistance float64) float64 {
+		return SteinhartCalculation(resistance, coeff)
+	}
 }
 
 func CheckDeviation(points []models.ThermistorPoint, coeff [3]float64) ([]models.DeviationTable, float64, float64) {
+	return CheckModelDeviation(points, SteinhartModel(coeff))
+}
+
+func CheckModelDeviation(points []models.ThermistorPoint, model Model) ([]models.DeviationTable, float64, float64) {
 	var fullTable []models.DeviationTable
 	var maxDev, avgDev float64 = 0, 0
 
 	for _, p := range points {
-		tTemp := SteinhartCalculation(p.Resistance, coeff) - models.KelvinToCelsius
+		tTemp := model(p.Resistance) - models.KelvinToCelsius
 		deviation := p.Temp - tTemp
-		fullTable = append(fullTable, models.DeviationTable{	
-			Resistance: p.Resistance,
-			TemperatureCSV: p.Temp,
+		fullTable = append(fullTable, models.DeviationTable{
+			Resistance:      p.Resistance,
+			TemperatureCSV:  p.Temp,
 			TemperatureCalc: tTemp,
-			Deviation: deviation})
+			Deviation:       deviation})
 
 		if math.Abs(deviation) > maxDev {
 			maxDev = math.Abs(deviation)
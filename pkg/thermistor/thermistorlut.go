@@ -2,11 +2,14 @@ package thermistor
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/Eriosies/thermistor-lut-gen/models"
 )
 
-func getResistanceFromADCValue(vRef float64, adcValue uint, adcBits uint, rSeries float64, rParallel float64) float64 {
+// getResistanceFromADCValue returns the thermistor resistance (Ω) read at adcValue by an ADC of
+// reference vRef on a low side divider supplied from vSupply.
+func getResistanceFromADCValue(vRef float64, vSupply float64, adcValue uint, adcBits uint, rSeries float64, rParallel float64) float64 {
 	var resistance float64
 	var adcMax uint = (1 << adcBits) - 1
 
@@ -18,12 +21,53 @@ func getResistanceFromADCValue(vRef float64, adcValue uint, adcBits uint, rSerie
 	}
 
 	vADC := vRef * float64(adcValue) / float64(adcMax+1)
+	// With a supply below the reference the top codes are out of reach.
+	if vADC >= vSupply {
+		return models.ResistanceMax
+	}
 
-	rTemp := rSeries * (vADC / (vRef - vADC))
+	rTemp := rSeries * (vADC / (vSupply - vADC))
 
 	if rParallel == 0 {
 		resistance = rTemp
 	} else {
+		// The parallel resistor caps the network, so a higher reading is out of range.
+		if rTemp >= rParallel {
+			return models.ResistanceMax
+		}
+		resistance = 1 / ((1 / rTemp) - (1 / rParallel))
+	}
+
+	return resistance
+}
+
+// getHighSideResistanceFromADCValue is getResistanceFromADCValue for the thermistor on the high
+// side of the divider, where the ADC code rises as the resistance falls.
+func getHighSideResistanceFromADCValue(vRef float64, vSupply float64, adcValue uint, adcBits uint, rSeries float64, rParallel float64) float64 {
+	var resistance float64
+	var adcMax uint = (1 << adcBits) - 1
+
+	if adcValue == 0 {
+		return models.ResistanceMax
+	}
+	if adcValue == adcMax {
+		return 0.0
+	}
+
+	vADC := vRef * float64(adcValue) / float64(adcMax+1)
+	if vADC >= vSupply {
+		return 0.0
+	}
+
+	rTemp := rSeries * ((vSupply - vADC) / vADC)
+
+	if rParallel == 0 {
+		resistance = rTemp
+	} else {
+		// The parallel resistor caps the network, so a higher reading is out of range.
+		if rTemp >= rParallel {
+			return models.ResistanceMax
+		}
 		resistance = 1 / ((1 / rTemp) - (1 / rParallel))
 	}
 
@@ -42,6 +86,10 @@ func clampTemperature(temperature float64, tempUpperLimit float64, tempLowerLimi
 }
 
 func GenerateLUT(cfg models.Config, coeff [3]float64) ([]float64, []float64, []uint, error) {
+	return GenerateModelLUT(cfg, SteinhartModel(coeff))
+}
+
+func GenerateModelLUT(cfg models.Config, model Model) ([]float64, []float64, []uint, error) {
 	adcValues := make([]uint, cfg.LUTSize)
 	resistanceValues := make([]float64, cfg.LUTSize)
 	tempValues := make([]float64, cfg.LUTSize)
@@ -57,13 +105,93 @@ func GenerateLUT(cfg models.Config, coeff [3]float64) ([]float64, []float64, []u
 
 	for i := uint(1); i < cfg.LUTSize-1; i++ {
 		adcValues[i] = i * stepSize
-		resistanceValues[i] = getResistanceFromADCValue(cfg.VoltageRef, adcValues[i], cfg.ADCResolution, cfg.RS*1000, cfg.RP*1000)
-		rawTemp := SteinhartCalculation(resistanceValues[i], coeff) - models.KelvinToCelsius
+		resistanceValues[i] = resistanceFromADCValue(cfg, adcValues[i])
+		rawTemp := model(resistanceValues[i]) - models.KelvinToCelsius
 		tempValues[i] = clampTemperature(rawTemp, cfg.UpperLimitTemp, cfg.LowerLimitTemp)
 	}
 
+	// ADC code 0 is the lowest resistance on the low side and the highest on the high side:
+	// hottest for a low side NTC, coldest for a low side PTC.
 	tempValues[0] = cfg.UpperLimitTemp
 	tempValues[cfg.LUTSize-1] = cfg.LowerLimitTemp
+	if !lowCodeIsHot(cfg) {
+		tempValues[0], tempValues[cfg.LUTSize-1] = tempValues[cfg.LUTSize-1], tempValues[0]
+	}
 
 	return tempValues, resistanceValues, adcValues, nil
 }
+
+// LUTError returns the largest error (K) of reading every ADC code whose model temperature lies
+// within the limits of cfg through lut, which holds the temperature at the start of each step,
+// rather than through the model itself. The length of lut must be a power of two from 2 up to
+// the number of ADC codes.
+func LUTError(cfg models.Config, model Model, lut []float64) (float64, error) {
+	size := len(lut)
+	if size < 2 || size&(size-1) != 0 || size > 1<<cfg.ADCResolution {
+		return 0, fmt.Errorf("LUT size %d is not a power of two from 2 to %d", size, 1<<cfg.ADCResolution)
+	}
+	return lutError(modelTemps(cfg, model), lut, cfg.ADCResolution), nil
+}
+
+// SelectLUTSize returns the smallest power of two LUT size whose LUTError is within maxErr (K),
+// with the error of each size tried, smallest first.
+func SelectLUTSize(cfg models.Config, model Model, maxErr float64) (uint, []float64, error) {
+	if maxErr <= 0 {
+		return 0, nil, fmt.Errorf("LUT error target must be greater than 0")
+	}
+
+	temps := modelTemps(cfg, model)
+	var errs []float64
+
+	for size := uint(2); size <= uint(1)<<cfg.ADCResolution; size <<= 1 {
+		cfg.LUTSize = size
+		lut, _, _, err := GenerateModelLUT(cfg, model)
+		if err != nil {
+			return 0, errs, err
+		}
+
+		errs = append(errs, lutError(temps, lut, cfg.ADCResolution))
+		if errs[len(errs)-1] <= maxErr {
+			return size, errs, nil
+		}
+	}
+	return 0, errs, fmt.Errorf("no LUT size reaches %.3g K", maxErr)
+}
+
+// modelTemps returns the model temperature (°C) at every ADC code, NaN outside the limits of cfg.
+func modelTemps(cfg models.Config, model Model) []float64 {
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+	temps := make([]float64, adcMax+1)
+
+	for adc := range temps {
+		temps[adc] = math.NaN()
+		if adc == 0 || uint(adc) == adcMax {
+			continue
+		}
+
+		resistance := resistanceFromADCValue(cfg, uint(adc))
+		if temp := model(resistance) - models.KelvinToCelsius; temp >= cfg.LowerLimitTemp && temp <= cfg.UpperLimitTemp {
+			temps[adc] = temp
+		}
+	}
+	return temps
+}
+
+// lutError is LUTError for the model temperatures at every ADC code and a valid LUT size.
+func lutError(temps, lut []float64, adcBits uint) float64 {
+	shift := adcBits - uint(math.Log2(float64(len(lut))))
+
+	var worst float64
+	for adc, temp := range temps {
+		if !math.IsNaN(temp) {
+			worst = math.Max(worst, math.Abs(lut[adc>>shift]-temp))
+		}
+	}
+	return worst
+}
+
+// IsPTC reports whether the sensor of cfg, a silicon PTC or an RTD, rises in resistance with
+// temperature.
+func IsPTC(cfg models.Config) bool {
+	return cfg.Sensor == models.SensorPTC || cfg.Sensor == models.SensorRTD
+}
@@ -1,6 +1,7 @@
 package thermistor
 
 import (
+	"math"
 	"testing"
 
 	"github.com/Eriosies/thermistor-lut-gen/models"
@@ -46,23 +47,23 @@ func TestGetResistanceFromADCValue(t *testing.T) {
 	}
 
 	for _, row := range vrTableRp {
-		rTest := getResistanceFromADCValue(cfg5vRp.VoltageRef, row.adcVal, cfg5vRp.ADCResolution, cfg5vRp.RS, cfg5vRp.RP)
+		rTest := getResistanceFromADCValue(cfg5vRp.VoltageRef, cfg5vRp.VoltageRef, row.adcVal, cfg5vRp.ADCResolution, cfg5vRp.RS, cfg5vRp.RP)
 		if !floatAlmostEqualPercentage(rTest, row.resistance, 0.005) {
 			t.Errorf("Expected %f, got %f", row.resistance, rTest)
 		}
 	}
 
 	for _, row := range vrTableRs {
-		rTest := getResistanceFromADCValue(cfg3v3Rs.VoltageRef, row.adcVal, cfg3v3Rs.ADCResolution, cfg3v3Rs.RS, cfg3v3Rs.RP)
+		rTest := getResistanceFromADCValue(cfg3v3Rs.VoltageRef, cfg3v3Rs.VoltageRef, row.adcVal, cfg3v3Rs.ADCResolution, cfg3v3Rs.RS, cfg3v3Rs.RP)
 		if !floatAlmostEqualPercentage(rTest, row.resistance, 0.005) {
 			t.Errorf("Expected %f, got %f", row.resistance, rTest)
 		}
 	}
-	temp = getResistanceFromADCValue(cfg3v3Rs.VoltageRef, 0, cfg3v3Rs.ADCResolution, cfg3v3Rs.RS, cfg3v3Rs.RP)
+	temp = getResistanceFromADCValue(cfg3v3Rs.VoltageRef, cfg3v3Rs.VoltageRef, 0, cfg3v3Rs.ADCResolution, cfg3v3Rs.RS, cfg3v3Rs.RP)
 	if temp != 0 {
 		t.Errorf("Input of ADC max, expected 0, actual = %.3g", temp)
 	}
-	temp = getResistanceFromADCValue(cfg3v3Rs.VoltageRef, 4095, cfg3v3Rs.ADCResolution, cfg3v3Rs.RS, cfg3v3Rs.RP)
+	temp = getResistanceFromADCValue(cfg3v3Rs.VoltageRef, cfg3v3Rs.VoltageRef, 4095, cfg3v3Rs.ADCResolution, cfg3v3Rs.RS, cfg3v3Rs.RP)
 	if temp < models.ResistanceMax {
 		t.Errorf("Input of ADC val = 0, expected >= %.3g, actual = %.3g", models.ResistanceMax, temp)
 	}
@@ -146,3 +147,285 @@ func TestGenerateLUT_Error(t *testing.T) {
 		t.Fatalf("expected error for LUT size exceeding ADC max, got nil")
 	}
 }
+
+func TestSelectLUTSize(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		UpperLimitTemp: 85,
+		LowerLimitTemp: 0,
+	}
+	model := SteinhartModel(testSteinhartCoeff)
+
+	size, errs, err := SelectLUTSize(cfg, model, 0.25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size&(size-1) != 0 || uint(2)<<(len(errs)-1) != size {
+		t.Fatalf("size = %d after %d candidates; want the last power of two tried", size, len(errs))
+	}
+	if errs[len(errs)-1] > 0.25 {
+		t.Errorf("selected size %d has error %g K; want <= 0.25 K", size, errs[len(errs)-1])
+	}
+	if len(errs) > 1 && errs[len(errs)-2] <= 0.25 {
+		t.Errorf("size %d already met the target with %g K", size/2, errs[len(errs)-2])
+	}
+
+	cfg.LUTSize = size
+	lut, _, _, err := GenerateModelLUT(cfg, model)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := LUTError(cfg, model, lut); err != nil || got != errs[len(errs)-1] {
+		t.Errorf("LUTError = %g, %v; want %g", got, err, errs[len(errs)-1])
+	}
+
+	// One entry per ADC code reproduces the model.
+	cfg.LUTSize = 1 << cfg.ADCResolution
+	lut, _, _, err = GenerateModelLUT(cfg, model)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, err := LUTError(cfg, model, lut); err != nil || got > 1e-9 {
+		t.Errorf("full size LUT error = %g, %v; want 0", got, err)
+	}
+
+	// Only power of two sizes map onto the ADC codes.
+	for _, size := range []int{0, 1, 48, 2 << cfg.ADCResolution} {
+		if _, err := LUTError(cfg, model, make([]float64, size)); err == nil {
+			t.Errorf("expected an error for a LUT of %d entries", size)
+		}
+	}
+
+	if _, _, err := SelectLUTSize(cfg, model, 0); err == nil {
+		t.Errorf("expected an error for a zero target")
+	}
+}
+
+func TestResistanceFromADCValue_Topology(t *testing.T) {
+	for _, topology := range []string{models.TopologyLow, models.TopologyHigh} {
+		cfg := models.Config{ADCResolution: 12, VoltageRef: 3.3, RS: 10, RP: 100, Topology: topology}
+
+		for _, r := range []float64{1000, 10000, 50000} {
+			adc := ADCValueFromResistance(cfg, r)
+			if back := resistanceFromADCValue(cfg, adc); !floatAlmostEqualPercentage(back, r, 0.01) {
+				t.Errorf("%s: resistance %g -> ADC %d -> %g", topology, r, adc, back)
+			}
+		}
+	}
+
+	// Half scale reads the series resistor on either side; the code rises with resistance on the
+	// low side and falls on the high side.
+	low := models.Config{ADCResolution: 12, VoltageRef: 3.3, RS: 10}
+	high := low
+	high.Topology = models.TopologyHigh
+
+	if r := resistanceFromADCValue(high, 2048); !floatAlmostEqualPercentage(r, 10000, 1e-9) {
+		t.Errorf("high side at half scale = %g; want 10000", r)
+	}
+	if ADCValueFromResistance(low, 5000) >= ADCValueFromResistance(low, 20000) {
+		t.Errorf("low side code should rise with resistance")
+	}
+	if ADCValueFromResistance(high, 5000) <= ADCValueFromResistance(high, 20000) {
+		t.Errorf("high side code should fall with resistance")
+	}
+
+	if r := resistanceFromADCValue(high, 0); r != models.ResistanceMax {
+		t.Errorf("high side at code 0 = %g; want %g", r, models.ResistanceMax)
+	}
+	if r := resistanceFromADCValue(high, 4095); r != 0 {
+		t.Errorf("high side at full scale = %g; want 0", r)
+	}
+}
+
+func TestGenerateModelLUT_HighSide(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		LUTSize:        256,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+		Topology:       models.TopologyHigh,
+	}
+
+	temps, _, _, err := GenerateModelLUT(cfg, SteinhartModel(testSteinhartCoeff))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An NTC on the high side reads cold at code 0 and the temperature rises with the code.
+	if temps[0] != cfg.LowerLimitTemp || temps[len(temps)-1] != cfg.UpperLimitTemp {
+		t.Errorf("LUT ends = %g, %g; want %g, %g", temps[0], temps[len(temps)-1], cfg.LowerLimitTemp, cfg.UpperLimitTemp)
+	}
+	for i := 1; i < len(temps); i++ {
+		if temps[i] < temps[i-1] {
+			t.Fatalf("LUT falls at entry %d: %g -> %g", i, temps[i-1], temps[i])
+		}
+	}
+	if !floatAlmostEqual(temps[128], 25, 0.5) {
+		t.Errorf("LUT at half scale = %g; want about 25 °C", temps[128])
+	}
+}
+
+func TestGenerateModelLUT_HighSideParallel(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		RP:             50,
+		LUTSize:        256,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+		Topology:       models.TopologyHigh,
+	}
+
+	// Low codes read above the 50k parallel resistor, which the network cannot reach.
+	if r := resistanceFromADCValue(cfg, 100); r != models.ResistanceMax {
+		t.Errorf("reading above the parallel resistor = %g; want %g", r, models.ResistanceMax)
+	}
+	if r := dividerResistance(models.TopologyHigh, 0.1, 10000, 50000); r != models.ResistanceMax {
+		t.Errorf("dividerResistance above the parallel resistor = %g; want %g", r, models.ResistanceMax)
+	}
+
+	temps, _, _, err := GenerateModelLUT(cfg, SteinhartModel(testSteinhartCoeff))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, temp := range temps {
+		if math.IsNaN(temp) || temp < cfg.LowerLimitTemp || temp > cfg.UpperLimitTemp {
+			t.Fatalf("LUT entry %d = %g; want within the limits", i, temp)
+		}
+	}
+	if temps[0] != cfg.LowerLimitTemp {
+		t.Errorf("LUT at code 0 = %g; want the lower limit %g", temps[0], cfg.LowerLimitTemp)
+	}
+}
+
+func TestResistanceFromADCValue_Bridge(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution:  16,
+		VoltageRef:     2.5,
+		RS:             10,
+		Topology:       models.TopologyBridge,
+		BridgeTop:      10,
+		BridgeBottom:   10,
+		AmpGain:        8,
+		UpperLimitTemp: 50,
+		LowerLimitTemp: 0,
+		LUTSize:        256,
+	}
+
+	// A balanced bridge reads zero.
+	if adc := ADCValueFromResistance(cfg, 10000); models.SignedADCValue(cfg, adc) != 0 {
+		t.Errorf("balanced bridge reads %d; want 0", models.SignedADCValue(cfg, adc))
+	}
+
+	for _, offset := range []float64{0, 0.1} {
+		cfg.AmpOffset = offset
+		for _, r := range []float64{8000, 10000, 12000} {
+			adc := ADCValueFromResistance(cfg, r)
+			if back := resistanceFromADCValue(cfg, adc); !floatAlmostEqualPercentage(back, r, 0.001) {
+				t.Errorf("offset %g: resistance %g -> ADC %d -> %g", offset, r, models.SignedADCValue(cfg, adc), back)
+			}
+		}
+	}
+
+	// Readings beyond the thermistor leg's supply are clipped.
+	cfg.AmpOffset = 0
+	cfg.AmpGain = 0.5
+	if r := resistanceFromADCValue(cfg, 65000); r != models.ResistanceMax {
+		t.Errorf("reading above the supply = %g; want %g", r, models.ResistanceMax)
+	}
+
+	cfg.AmpGain = 8
+	temps, _, adcs, err := GenerateModelLUT(cfg, SteinhartModel(testSteinhartCoeff))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if models.SignedADCValue(cfg, adcs[128]) != 0 || !floatAlmostEqual(temps[128], 25, 0.1) {
+		t.Errorf("LUT at code %d = %g °C; want code 0 at 25 °C", models.SignedADCValue(cfg, adcs[128]), temps[128])
+	}
+	if temps[0] != cfg.UpperLimitTemp {
+		t.Errorf("most negative reading = %g °C; want the upper limit", temps[0])
+	}
+}
+
+func TestResistanceFromADCValue_Current(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution: 16,
+		VoltageRef:    3.3,
+		Topology:      models.TopologyCurrent,
+		Excitation:    100,
+	}
+
+	// 10 kΩ at 100 µA develops 1 V.
+	if adc := ADCValueFromResistance(cfg, 10000); !floatAlmostEqual(float64(adc), 65536/3.3, 1) {
+		t.Errorf("ADC at 10 kΩ = %d; want %.0f", adc, 65536/3.3)
+	}
+
+	// Ratiometric: the reading is a fraction of the reference resistor whatever the current.
+	cfg.RRef = 20
+	for _, excitation := range []float64{50, 100} {
+		cfg.Excitation = excitation
+		if adc := ADCValueFromResistance(cfg, 10000); adc != 32768 {
+			t.Errorf("%g µA: ADC at half the reference resistor = %d; want 32768", excitation, adc)
+		}
+	}
+
+	for _, rp := range []float64{0, 47} {
+		cfg.RP = rp
+		for _, r := range []float64{1000, 10000, 19000} {
+			adc := ADCValueFromResistance(cfg, r)
+			if back := resistanceFromADCValue(cfg, adc); !floatAlmostEqualPercentage(back, r, 0.001) {
+				t.Errorf("rp %g: resistance %g -> ADC %d -> %g", rp, r, adc, back)
+			}
+		}
+	}
+
+	// A reading above the parallel resistor alone is out of range.
+	cfg.RRef, cfg.RP = 100, 10
+	if r := resistanceFromADCValue(cfg, 40000); r != models.ResistanceMax {
+		t.Errorf("reading above the parallel resistor = %g; want %g", r, models.ResistanceMax)
+	}
+
+	cfg.RP = 0
+	if p := SelfHeatingPower(cfg, 10000); !floatAlmostEqual(p, 100e-6*100e-6*10000, 1e-12) {
+		t.Errorf("SelfHeatingPower = %g; want I²R", p)
+	}
+}
+
+func TestResistanceFromADCValue_SeparateSupply(t *testing.T) {
+	for _, topology := range []string{models.TopologyLow, models.TopologyHigh, models.TopologyBridge} {
+		cfg := models.Config{
+			ADCResolution: 16,
+			VoltageRef:    2.5,
+			VoltageSupply: 3.3,
+			RS:            10,
+			Topology:      topology,
+			BridgeTop:     10,
+			BridgeBottom:  10,
+			AmpGain:       1,
+		}
+
+		for _, r := range []float64{5000, 10000, 15000} {
+			adc := ADCValueFromResistance(cfg, r)
+			if back := resistanceFromADCValue(cfg, adc); !floatAlmostEqualPercentage(back, r, 0.001) {
+				t.Errorf("%s: resistance %g -> ADC %d -> %g", topology, r, adc, back)
+			}
+		}
+	}
+
+	// Half the 3.3 V supply reads as 1.65 V against the 2.5 V reference.
+	cfg := models.Config{ADCResolution: 16, VoltageRef: 2.5, VoltageSupply: 3.3, RS: 10}
+	if adc := ADCValueFromResistance(cfg, 10000); !floatAlmostEqual(float64(adc), 65536*1.65/2.5, 1) {
+		t.Errorf("ADC at the divider midpoint = %d; want %.0f", adc, 65536*1.65/2.5)
+	}
+	if r := getResistanceFromADCValue(2.5, 2.0, 60000, 16, 10000, 0); r != models.ResistanceMax {
+		t.Errorf("reading above the supply = %g; want %g", r, models.ResistanceMax)
+	}
+	if p := SelfHeatingPower(cfg, 10000); !floatAlmostEqual(p, 1.65*1.65/10000, 1e-12) {
+		t.Errorf("SelfHeatingPower = %g; want %g", p, 1.65*1.65/10000)
+	}
+}
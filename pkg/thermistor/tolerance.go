@@ -0,0 +1,79 @@
+package thermistor
+
+import (
+	"math"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// ToleranceBounds returns, for each ADC code, the lowest and highest temperature (°C) a part
+// within the cfg tolerances can actually be at when the firmware reads that code. Every corner
+// of the R25, B and series resistor tolerances is evaluated through the divider and model.
+// The B tolerance scales ln(R/R25) and only applies to NTC models. Codes 0 and full scale are
+// the clamped ends of the LUT, so their bounds are the LUT limits.
+func ToleranceBounds(cfg models.Config, model Model, adcValues []uint) ([]float64, []float64, error) {
+	minTemps := make([]float64, len(adcValues))
+	maxTemps := make([]float64, len(adcValues))
+
+	r25, err := ResistanceFromTemperature(model, models.BetaReferenceTemp+KelvinToCelsius)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tolB := cfg.TolB / 100
+	if IsPTC(cfg) {
+		tolB = 0
+	}
+
+	adcMax := uint((1 << cfg.ADCResolution) - 1)
+	signs := []float64{-1, 1}
+
+	for i, adc := range adcValues {
+		if adc == 0 || adc >= adcMax {
+			minTemps[i], maxTemps[i] = endpointTemp(cfg, model, adc), endpointTemp(cfg, model, adc)
+			continue
+		}
+
+		minTemps[i], maxTemps[i] = math.Inf(1), math.Inf(-1)
+		for _, sRS := range signs {
+			corner := cfg
+			corner.RS = cfg.RS * (1 + sRS*cfg.TolRS/100)
+			resistance := resistanceFromADCValue(corner, adc)
+
+			for _, sR := range signs {
+				for _, sB := range signs {
+					// Resistance the nominal part would have at the same temperature.
+					lnRatio := math.Log(resistance/(r25*(1+sR*cfg.TolR25/100))) / (1 + sB*tolB)
+					temp := model(r25*math.Exp(lnRatio)) - KelvinToCelsius
+
+					minTemps[i] = math.Min(minTemps[i], temp)
+					maxTemps[i] = math.Max(maxTemps[i], temp)
+				}
+			}
+		}
+	}
+
+	return minTemps, maxTemps, nil
+}
+
+// endpointTemp returns the clamped LUT temperature at ADC code 0 or full scale.
+func endpointTemp(cfg models.Config, model Model, adc uint) float64 {
+	if (adc == 0) == lowCodeIsHot(cfg) {
+		return cfg.UpperLimitTemp
+	}
+	return cfg.LowerLimitTemp
+}
+
+// ToleranceBand returns the largest error (K) below and above the nominal temperatures over
+// the entries of the LUT that lie strictly inside the temperature limits.
+func ToleranceBand(cfg models.Config, nominal, minTemps, maxTemps []float64) (float64, float64) {
+	var below, above float64
+	for i, t := range nominal {
+		if t <= cfg.LowerLimitTemp || t >= cfg.UpperLimitTemp {
+			continue
+		}
+		below = math.Max(below, t-minTemps[i])
+		above = math.Max(above, maxTemps[i]-t)
+	}
+	return below, above
+}
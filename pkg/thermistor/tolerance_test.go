@@ -0,0 +1,69 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestToleranceBounds(t *testing.T) {
+	cfg := models.Config{
+		ADCResolution:  12,
+		VoltageRef:     3.3,
+		RS:             10,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+		TolR25:         1,
+	}
+	model := BetaModel(testBeta)
+
+	// Code 2048 reads 10 kΩ = R25 with a 10 kΩ series resistor.
+	adcValues := []uint{0, 2048, 4095}
+
+	minTemps, maxTemps, err := ToleranceBounds(cfg, model, adcValues)
+	if err != nil {
+		t.Fatalf("ToleranceBounds returned error: %v", err)
+	}
+
+	wantMin := BetaCalculation(10000/0.99, testBeta) - KelvinToCelsius
+	wantMax := BetaCalculation(10000/1.01, testBeta) - KelvinToCelsius
+	if !floatAlmostEqual(minTemps[1], wantMin, 1e-6) || !floatAlmostEqual(maxTemps[1], wantMax, 1e-6) {
+		t.Errorf("R25 bounds = %f..%f; want %f..%f", minTemps[1], maxTemps[1], wantMin, wantMax)
+	}
+
+	if minTemps[0] != cfg.UpperLimitTemp || maxTemps[2] != cfg.LowerLimitTemp {
+		t.Errorf("endpoints = %f, %f; want the LUT limits", minTemps[0], maxTemps[2])
+	}
+
+	// The B tolerance pivots around R25, so it has no effect there.
+	cfg.TolR25, cfg.TolB = 0, 1
+	minTemps, maxTemps, err = ToleranceBounds(cfg, model, adcValues)
+	if err != nil {
+		t.Fatalf("ToleranceBounds returned error: %v", err)
+	}
+	if !floatAlmostEqual(minTemps[1], 25, 1e-6) || !floatAlmostEqual(maxTemps[1], 25, 1e-6) {
+		t.Errorf("B bounds at R25 = %f..%f; want 25", minTemps[1], maxTemps[1])
+	}
+
+	// Series resistor tolerance alone shifts the resistance the firmware sees.
+	cfg.TolB, cfg.TolRS = 0, 1
+	minTemps, maxTemps, err = ToleranceBounds(cfg, model, adcValues)
+	if err != nil {
+		t.Fatalf("ToleranceBounds returned error: %v", err)
+	}
+	if !floatAlmostEqual(minTemps[1], BetaCalculation(10100, testBeta)-KelvinToCelsius, 1e-6) || !floatAlmostEqual(maxTemps[1], BetaCalculation(9900, testBeta)-KelvinToCelsius, 1e-6) {
+		t.Errorf("RS bounds = %f..%f", minTemps[1], maxTemps[1])
+	}
+}
+
+func TestToleranceBand(t *testing.T) {
+	cfg := models.Config{UpperLimitTemp: 125, LowerLimitTemp: -40}
+	nominal := []float64{125, 50, 25, -40}
+	minTemps := []float64{125, 49.5, 24.8, -40}
+	maxTemps := []float64{130, 50.2, 25.4, -40}
+
+	below, above := ToleranceBand(cfg, nominal, minTemps, maxTemps)
+	if !floatAlmostEqual(below, 0.5, 1e-9) || !floatAlmostEqual(above, 0.4, 1e-9) {
+		t.Errorf("band = -%f/+%f; want -0.5/+0.4", below, above)
+	}
+}
@@ -0,0 +1,36 @@
+package thermistor
+
+import "github.com/Eriosies/thermistor-lut-gen/models"
+
+// VrefFromVrefint returns the ADC reference (V) measured through the internal reference: its
+// reading vrefint and the factory calibration value vrefintCal taken at calVref (V), both at the
+// same resolution.
+func VrefFromVrefint(vrefint, vrefintCal uint, calVref float64) float64 {
+	if vrefint == 0 {
+		return 0
+	}
+	return calVref * float64(vrefintCal) / float64(vrefint)
+}
+
+// ResistanceAtVref returns the thermistor resistance (Ω) read at adcValue with the ADC reference
+// measured as vref (V) rather than taken as cfg.VoltageRef, as the _vref functions generated with
+// cfg.RuntimeVref compute it. A divider or bridge without its own supply follows the reference.
+func ResistanceAtVref(cfg models.Config, adcValue uint, vref float64) float64 {
+	cfg.VoltageRef = vref
+	return resistanceFromADCValue(cfg, adcValue)
+}
+
+// TemperatureAtVref returns the temperature (°C) model reads at adcValue with the ADC reference
+// measured as vref (V), clamped to the limits of cfg.
+func TemperatureAtVref(cfg models.Config, model Model, adcValue uint, vref float64) float64 {
+	r := ResistanceAtVref(cfg, adcValue, vref)
+	return clampTemperature(model(r)-KelvinToCelsius, cfg.UpperLimitTemp, cfg.LowerLimitTemp)
+}
+
+// ADCValueAtVref returns the ADC code a board whose reference is actually vref (V) reads at
+// tempC, for test vectors of the runtime compensated conversion.
+func ADCValueAtVref(cfg models.Config, model Model, tempC, vref float64) (uint, error) {
+	cfg.VoltageRef = vref
+	adc, _, err := ADCValueFromTemperature(cfg, model, tempC)
+	return adc, err
+}
@@ -0,0 +1,57 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestVrefFromVrefint(t *testing.T) {
+	// A reading of the internal reference 10% above its calibration means a 10% lower supply.
+	if vref := VrefFromVrefint(1650, 1500, 3.0); !floatAlmostEqual(vref, 3.0*1500/1650, 1e-12) {
+		t.Errorf("VrefFromVrefint = %g; want %g", vref, 3.0*1500/1650)
+	}
+	if vref := VrefFromVrefint(0, 1500, 3.0); vref != 0 {
+		t.Errorf("VrefFromVrefint with no reading = %g; want 0", vref)
+	}
+}
+
+func TestTemperatureAtVref(t *testing.T) {
+	model := SteinhartModel(testSteinhartCoeff)
+	cfg := models.Config{
+		ADCResolution:  16,
+		VoltageRef:     3.3,
+		RS:             10,
+		UpperLimitTemp: 125,
+		LowerLimitTemp: -40,
+	}
+
+	// A divider on the ADC reference is ratiometric: the code does not move with the supply.
+	nominal, err := ADCValueAtVref(cfg, model, 25, 3.3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, vref := range []float64{3.0, 3.6} {
+		adc, _ := ADCValueAtVref(cfg, model, 25, vref)
+		if adc != nominal {
+			t.Errorf("ratiometric code at %g V = %d; want %d", vref, adc, nominal)
+		}
+	}
+
+	// With its own supply the code moves, and only the measured reference reads it back.
+	cfg.VoltageSupply = 2.5
+	for _, vref := range []float64{3.0, 3.3, 3.6} {
+		adc, err := ADCValueAtVref(cfg, model, 25, vref)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if temp := TemperatureAtVref(cfg, model, adc, vref); !floatAlmostEqual(temp, 25, 0.01) {
+			t.Errorf("compensated temperature at %g V = %g; want 25", vref, temp)
+		}
+		if vref != cfg.VoltageRef {
+			if temp := TemperatureAtVref(cfg, model, adc, cfg.VoltageRef); floatAlmostEqual(temp, 25, 1) {
+				t.Errorf("uncompensated temperature at %g V = %g; want well off 25", vref, temp)
+			}
+		}
+	}
+}
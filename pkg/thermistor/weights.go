@@ -0,0 +1,83 @@
+package thermistor
+
+import (
+	"fmt"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+// Relative weight of points outside the temperature limits with the range strategy.
+const OutOfRangeWeight float64 = 0.01
+
+// WeightPoints returns a copy of points with Weight set for the fit. Points with an
+// uncertainty are weighted by 1/σ² in 1/T space (σ = Uncertainty/T²), and the range
+// strategy scales points outside lower..upper by OutOfRangeWeight.
+func WeightPoints(points []models.ThermistorPoint, strategy string, lower, upper float64) ([]models.ThermistorPoint, error) {
+	weighted := make([]models.ThermistorPoint, len(points))
+	copy(weighted, points)
+
+	var withUncertainty int
+	for _, p := range points {
+		if p.Uncertainty < 0 {
+			return nil, fmt.Errorf("negative uncertainty %g at %g °C", p.Uncertainty, p.Temp)
+		}
+		if p.Uncertainty > 0 {
+			withUncertainty++
+		}
+	}
+	if withUncertainty != 0 && withUncertainty != len(points) {
+		return nil, fmt.Errorf("uncertainty given for %d of %d points; give it for all or none", withUncertainty, len(points))
+	}
+
+	var sum float64
+	for i := range weighted {
+		p := &weighted[i]
+		p.Weight = 1.0
+
+		if p.Uncertainty > 0 {
+			tK := p.Temp + KelvinToCelsius
+			sigma := p.Uncertainty / (tK * tK)
+			p.Weight = 1 / (sigma * sigma)
+		}
+		sum += p.Weight
+	}
+
+	// Normalise to a mean weight of 1 so range scaling is relative.
+	for i := range weighted {
+		weighted[i].Weight *= float64(len(weighted)) / sum
+	}
+
+	switch strategy {
+	case models.WeightNone, "":
+	case models.WeightRange:
+		for i := range weighted {
+			if weighted[i].Temp < lower || weighted[i].Temp > upper {
+				weighted[i].Weight *= OutOfRangeWeight
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown weighting strategy %q", strategy)
+	}
+
+	return weighted, nil
+}
+
+// pointWeights returns the fit weights of points, or nil if none are weighted.
+func pointWeights(points []models.ThermistorPoint) []float64 {
+	var weighted bool
+	weights := make([]float64, len(points))
+
+	for i, p := range points {
+		weights[i] = p.Weight
+		if p.Weight == 0 {
+			weights[i] = 1.0
+		} else {
+			weighted = true
+		}
+	}
+
+	if !weighted {
+		return nil
+	}
+	return weights
+}
@@ -0,0 +1,116 @@
+package thermistor
+
+import (
+	"testing"
+
+	"github.com/Eriosies/thermistor-lut-gen/models"
+)
+
+func TestWeightedLeastSquares(t *testing.T) {
+	// Last row disagrees with the line y = 1 + x; a tiny weight should make it irrelevant.
+	testX := [][]float64{
+		{1, 0},
+		{1, 1},
+		{1, 2},
+		{1, 3},
+	}
+	testY := []float64{1, 2, 3, 10}
+
+	result, err := weightedLeastSquares(testX, testY, []float64{1, 1, 1, 1e-12})
+	if err != nil {
+		t.Fatalf("weightedLeastSquares returned error: %v", err)
+	}
+
+	expected := []float64{1, 1}
+	for i := range expected {
+		if !floatAlmostEqual(result[i], expected[i], 1e-6) {
+			t.Errorf("result[%d] = %f; want %f", i, result[i], expected[i])
+		}
+	}
+
+	if _, err := weightedLeastSquares(testX, testY, []float64{1, 1, -1, 1}); err == nil {
+		t.Errorf("expected error for negative weight")
+	}
+}
+
+func TestWeightPoints(t *testing.T) {
+	points := []models.ThermistorPoint{
+		{Temp: -40, Resistance: 195652, Uncertainty: 1.0},
+		{Temp: 25, Resistance: 10000, Uncertainty: 0.1},
+		{Temp: 125, Resistance: 531, Uncertainty: 1.0},
+	}
+
+	weighted, err := WeightPoints(points, models.WeightNone, -40, 125)
+	if err != nil {
+		t.Fatalf("WeightPoints returned error: %v", err)
+	}
+
+	if weighted[1].Weight <= weighted[0].Weight || weighted[1].Weight <= weighted[2].Weight {
+		t.Errorf("25 °C point should have the highest weight, got %v", weighted)
+	}
+
+	if points[0].Weight != 0 {
+		t.Errorf("WeightPoints modified its input")
+	}
+
+	ranged, err := WeightPoints(points, models.WeightRange, 0, 85)
+	if err != nil {
+		t.Fatalf("WeightPoints returned error: %v", err)
+	}
+
+	if !floatAlmostEqualPercentage(ranged[0].Weight, weighted[0].Weight*OutOfRangeWeight, 1e-9) {
+		t.Errorf("out of range weight = %g, want %g", ranged[0].Weight, weighted[0].Weight*OutOfRangeWeight)
+	}
+	if ranged[1].Weight != weighted[1].Weight {
+		t.Errorf("in range weight changed: %g != %g", ranged[1].Weight, weighted[1].Weight)
+	}
+}
+
+func TestWeightPoints_Errors(t *testing.T) {
+	partial := []models.ThermistorPoint{
+		{Temp: 0, Resistance: 27219, Uncertainty: 0.1},
+		{Temp: 25, Resistance: 10000},
+		{Temp: 50, Resistance: 4161},
+	}
+
+	if _, err := WeightPoints(partial, models.WeightNone, 0, 50); err == nil {
+		t.Errorf("expected error for partial uncertainty column")
+	}
+
+	if _, err := WeightPoints(testPoints, "bogus", 0, 50); err == nil {
+		t.Errorf("expected error for unknown strategy")
+	}
+}
+
+func TestFindSteinhartCoefficients_RangeWeighted(t *testing.T) {
+	points := polyTestPoints()
+
+	unweighted, err := FindSteinhartCoefficients(points)
+	if err != nil {
+		t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+	}
+
+	weightedPoints, err := WeightPoints(points, models.WeightRange, 0, 85)
+	if err != nil {
+		t.Fatalf("WeightPoints returned error: %v", err)
+	}
+
+	weighted, err := FindSteinhartCoefficients(weightedPoints)
+	if err != nil {
+		t.Fatalf("FindSteinhartCoefficients returned error: %v", err)
+	}
+
+	var inRange []models.ThermistorPoint
+	for _, p := range points {
+		if p.Temp >= 0 && p.Temp <= 85 {
+			inRange = append(inRange, p)
+		}
+	}
+
+	_, unweightedDev, _ := CheckDeviation(inRange, unweighted)
+	_, weightedDev, _ := CheckDeviation(inRange, weighted)
+
+	if weightedDev >= unweightedDev {
+		t.Errorf("range weighted max deviation %.3g not better than unweighted %.3g", weightedDev, unweightedDev)
+	}
+}